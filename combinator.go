@@ -10,7 +10,7 @@ func Seq(parsers ...Parserish) Parser {
 	parserfied := ParsifyAll(parsers...)
 
 	return NewParser("Seq()", func(ps *State, node *Result) {
-		node.Child = make([]Result, len(parserfied))
+		node.Child = ps.Children(len(parserfied))
 		startpos := ps.Pos
 		for i, parser := range parserfied {
 			node.Child[i].Input = node.Input
@@ -25,7 +25,9 @@ func Seq(parsers ...Parserish) Parser {
 	})
 }
 
-// NoAutoWS disables automatically ignoring whitespace between tokens for all parsers underneath
+// NoAutoWS disables automatically ignoring whitespace between tokens for all parsers underneath.
+// This includes the leading whitespace normally skipped by literals such as StringLit and NumberLit,
+// so NoAutoWS(StringLit(`"`)) only matches a string that starts right at the current position.
 func NoAutoWS(parser Parserish) Parser {
 	parserfied := Parsify(parser)
 	return func(ps *State, node *Result) {
@@ -72,8 +74,10 @@ func Any(parsers ...Parserish) Parser {
 		}
 
 		ps.Error = Error{
-			pos:      longestError.pos,
-			expected: strings.Join(expected, " or "),
+			pos:          longestError.pos,
+			expected:     strings.Join(expected, " or "),
+			opener:       longestError.opener,
+			unterminated: longestError.unterminated,
 		}
 		ps.Pos = startpos
 	})
@@ -101,7 +105,7 @@ func manyImpl(min int, op Parserish, sep ...Parserish) Parser {
 	}
 
 	return func(ps *State, node *Result) {
-		node.Child = make([]Result, 0, 5)
+		node.Child = ps.Children(5)[:0]
 		startpos := ps.Pos
 		for {
 			node.Child = append(node.Child, Result{Input: node.Input})
@@ -162,6 +166,25 @@ func Bind(parser Parserish, val interface{}) Parser {
 	}
 }
 
+// Captured runs the given parser and replaces the .Token it returns with the exact input it consumed,
+// not counting leading whitespace, while keeping everything else it set, such as .Result. This is
+// useful for keeping the source text of a value, eg Captured(NumberLit()) gives both 0x1F and 31.
+func Captured(parser Parserish) Parser {
+	p := Parsify(parser)
+
+	return func(ps *State, node *Result) {
+		ps.WS(ps)
+		startpos := ps.Pos
+		p(ps, node)
+		if ps.Errored() {
+			return
+		}
+		node.Token = ps.Input[startpos:ps.Pos]
+		node.Start = startpos
+		node.End = ps.Pos
+	}
+}
+
 // Map applies the callback if the parser matches. This is used to set the Result
 // based on the matched result.
 func Map(parser Parserish, f func(n *Result)) Parser {
@@ -197,6 +197,29 @@ func TestBind(t *testing.T) {
 	})
 }
 
+func TestCaptured(t *testing.T) {
+	t.Run("number", func(t *testing.T) {
+		result, ps := runParser("  0x1F rest", Captured(NumberLit()))
+		require.Equal(t, "0x1F", result.Token)
+		require.Equal(t, int64(31), result.Result)
+		require.Equal(t, 2, result.Start)
+		require.Equal(t, 6, result.End)
+		require.Equal(t, " rest", ps.Get())
+	})
+
+	t.Run("sequence", func(t *testing.T) {
+		result, _ := runParser(`[1, "two" ,3]`, Captured(Seq("[", NumberLit(), ",", StringLit(`"`), ",", NumberLit(), "]")))
+		require.Equal(t, `[1, "two" ,3]`, result.Token)
+		require.Equal(t, "two", result.Child[3].Token)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		result, ps := runParser("abc", Captured(NumberLit()))
+		require.Equal(t, "", result.Token)
+		require.Equal(t, "offset 0: expected number", ps.Error.Error())
+	})
+}
+
 func TestCut(t *testing.T) {
 	t.Run("test any", func(t *testing.T) {
 		_, ps := runParser("var world", Any(Seq("var", Cut(), "hello"), "var world"))
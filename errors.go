@@ -11,13 +11,27 @@ import (
 type Error struct {
 	pos      int
 	expected string
+	// opener is one past the position of the opening quote of an unterminated literal, so that the
+	// zero value means there isn't one
+	opener int
+	// unterminated names the kind of literal that was never closed, eg string literal
+	unterminated string
 }
 
 // Pos is the offset into the document the error was found
 func (e *Error) Pos() int { return e.pos }
 
+// Opener returns the position of the opening quote or bracket of a literal that was never closed, for
+// which Pos is where the input ran out. It returns false for any other error.
+func (e *Error) Opener() (int, bool) { return e.opener - 1, e.opener > 0 }
+
 // Error satisfies the golang error interface
-func (e *Error) Error() string { return fmt.Sprintf("offset %d: expected %s", e.pos, e.expected) }
+func (e *Error) Error() string {
+	if e.unterminated != "" {
+		return fmt.Sprintf("offset %d: unterminated %s, expected closing %s", e.pos, e.unterminated, e.expected)
+	}
+	return fmt.Sprintf("offset %d: expected %s", e.pos, e.expected)
+}
 
 // UnparsedInputError is returned by Run when not all of the input was consumed. There may still be a valid result
 type UnparsedInputError struct {
@@ -2,269 +2,2827 @@ package goparsify
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"regexp/syntax"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
-func stringImpl(ps *State, node *Result, closer rune, escapes map[rune]rune) bool {
+// StringOption enables optional behaviour in CustomStringLiteral
+type StringOption func(*stringOptions)
+
+type stringOptions struct {
+	octal            bool
+	lineContinuation bool
+	strict           bool
+	doubledQuotes    bool
+	noControlChars   bool
+	recordEscapes    bool
+	countEscapes     bool
+	nested           bool
+	mixedDelimiters  bool
+	percent          bool
+	keepQuotes       bool
+	recoverEscapes   bool
+	nonASCII         bool
+	foldWhitespace   bool
+	// bytes is set for byte strings, which have no unicode escapes
+	bytes bool
+	// verbatim is set for regexps, which only have escapes for the closer and those in the escapes map,
+	// leaving the rest for the regexp engine
+	verbatim bool
+	// compile is set when regexp patterns should be compiled, with compileFlags in front of them
+	compile      bool
+	compileFlags string
+	// flags are the letters that may follow a regexp literal
+	flags string
+	// maxLength limits the number of runes in the decoded string when it is above 0
+	maxLength int
+	// maxScan limits the number of runes of input between the quotes when it is above 0
+	maxScan int
+	// maxNesting limits how deeply nested brackets may go, defaulting to defaultMaxNesting
+	maxNesting int
+	// opener is counted against the closer when nested is set and they differ, so that brackets
+	// inside the string can be balanced
+	opener rune
+	// escape introduces escape sequences in place of the usual backslash
+	escape rune
+	// terminator replaces the closer when a string ends with more than one rune, eg """
+	terminator string
+	// literal names the kind of string in errors, defaulting to string literal
+	literal string
+	// escapeFunc is tried on each escape before the built in escapes
+	escapeFunc func(ps *State, after rune) (string, int, bool)
+	// namedEscapes are the names given to NamedEscapes, longest first
+	namedEscapes []string
+	names        map[string]string
+}
+
+// OctalEscapes allows escapes of one to three octal digits, eg \0, \12 or \101, which are written as a
+// single byte
+func OctalEscapes() StringOption {
+	return func(o *stringOptions) {
+		o.octal = true
+	}
+}
+
+// LineContinuations makes a backslash at the end of a line join it to the next, as in C and shell.
+// Both the backslash and the newline (\n or \r\n) are dropped from the result.
+func LineContinuations() StringOption {
+	return func(o *stringOptions) {
+		o.lineContinuation = true
+	}
+}
+
+// StrictEscapes makes an unknown escape sequence an error instead of passing the backslash and the
+// following character through untouched. The escape character itself and / can always be escaped,
+// standing for themselves, so that JSON strings like "C:\\dir" and "a\/b" are accepted.
+func StrictEscapes() StringOption {
+	return func(o *stringOptions) {
+		o.strict = true
+	}
+}
+
+// DoubledQuotes lets the closing quote be escaped by writing it twice, as in SQL's 'it''s' or CSV's
+// "say ""hi""". Backslash escapes still work unless EscapeWith picks something else.
+func DoubledQuotes() StringOption {
+	return func(o *stringOptions) {
+		o.doubledQuotes = true
+	}
+}
+
+// NoControlChars makes an unescaped newline or other control character below 0x20 inside the string an
+// error, as JSON requires.
+func NoControlChars() StringOption {
+	return func(o *stringOptions) {
+		o.noControlChars = true
+	}
+}
+
+// RecordEscapes adds a .Child to the result for every escape sequence in the string, holding its
+// decoded value in .Token and its position in the input in .Start and .End.
+func RecordEscapes() StringOption {
+	return func(o *stringOptions) {
+		o.recordEscapes = true
+	}
+}
+
+// CountEscapes sets .Escapes in the result to the number of escape sequences that were decoded. A
+// string with none is returned as a slice of the input rather than a copy.
+func CountEscapes() StringOption {
+	return func(o *stringOptions) {
+		o.countEscapes = true
+	}
+}
+
+// NestedDelimiters lets bracket delimiters nest, so that {a{b}c} matches a{b}c rather than stopping at
+// the first }, up to the depth allowed by MaxNesting. It has no effect when the opening and closing
+// quotes are the same.
+func NestedDelimiters() StringOption {
+	return func(o *stringOptions) {
+		o.nested = true
+	}
+}
+
+// MixedDelimiters lets the replacement part of a regexp replace literal use different delimiters
+// from the pattern, eg {pat}/repl/. Without it both parts must use the same delimiters, eg
+// {pat}{repl}.
+func MixedDelimiters() StringOption {
+	return func(o *stringOptions) {
+		o.mixedDelimiters = true
+	}
+}
+
+// defaultMaxNesting is how deeply NestedDelimiters lets brackets nest unless MaxNesting says otherwise
+const defaultMaxNesting = 1000
+
+// MaxNesting limits how deeply brackets may be nested inside a string using NestedDelimiters, so that
+// untrusted input can't make the parser track an unbounded depth. It defaults to 1000.
+func MaxNesting(n int) StringOption {
+	return func(o *stringOptions) {
+		o.maxNesting = n
+	}
+}
+
+// FoldWhitespace collapses each run of whitespace in the string, including newlines, to a single
+// space, as in YAML's folded scalars, so that a long string can be wrapped over several lines. Runs
+// at the start and end are collapsed too rather than removed, and whitespace written with escapes,
+// eg \n, is kept as it is. NoControlChars still applies, so with it newlines and tabs are errors and
+// only spaces are folded.
+func FoldWhitespace() StringOption {
+	return func(o *stringOptions) {
+		o.foldWhitespace = true
+	}
+}
+
+// MaxLength limits the string to at most n runes once escapes have been decoded, so that untrusted
+// input can't make the parser hold on to huge strings.
+func MaxLength(n int) StringOption {
+	return func(o *stringOptions) {
+		o.maxLength = n
+	}
+}
+
+// MaxScan limits the string to at most n runes as written between the quotes, escapes and all, so
+// that the work done on untrusted input is bounded even when it has no closing quote.
+func MaxScan(n int) StringOption {
+	return func(o *stringOptions) {
+		o.maxScan = n
+	}
+}
+
+// EscapeWith makes escape sequences start with the given rune instead of a backslash, eg ~n rather than
+// \n. It should not be the same as the closing quote.
+func EscapeWith(escape rune) StringOption {
+	return func(o *stringOptions) {
+		o.escape = escape
+	}
+}
+
+// EscapeFunc lets fn decode escape sequences that the escapes map can't express, eg ones that stand for
+// more than one character or depend on what follows. fn is called for every escape with the rune after
+// the escape character, and ps.Pos pointing at it so that the rest of the input can be examined. It
+// returns the text to replace the escape with and how many runes after the escape character it used,
+// or false to leave the escape to the usual rules. To reject an escape fn can raise an error on ps.
+func EscapeFunc(fn func(ps *State, after rune) (string, int, bool)) StringOption {
+	return func(o *stringOptions) {
+		o.escapeFunc = fn
+	}
+}
+
+// NamedEscapes allows escapes made of a name rather than a single character, eg \null or \newline,
+// each replaced by its value in names. When several names match the longest is used, and when none
+// do the escape falls back to the single character escapes.
+func NamedEscapes(names map[string]string) StringOption {
+	sorted := make([]string, 0, len(names))
+	copied := make(map[string]string, len(names))
+	for name, value := range names {
+		if name == "" {
+			continue
+		}
+		sorted = append(sorted, name)
+		copied[name] = value
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	return func(o *stringOptions) {
+		o.namedEscapes = sorted
+		o.names = copied
+	}
+}
+
+// PercentEscapes decodes URL style percent-encoding, where % and two hex digits stand for a byte, eg
+// %41 for A or %E2%82%AC for €. Every % must be followed by two hex digits, and no other escapes are
+// recognised, so the closing quote has to be written as a percent escape too.
+func PercentEscapes() StringOption {
+	return func(o *stringOptions) {
+		o.escape = '%'
+		o.percent = true
+	}
+}
+
+// KeepQuotes returns the whole string literal as it was written, including the quotes and any escapes,
+// in .Raw, alongside the decoded contents in .Token.
+func KeepQuotes() StringOption {
+	return func(o *stringOptions) {
+		o.keepQuotes = true
+	}
+}
+
+// CompileRegexp makes the regexp literals compile their pattern with regexp.Compile and return the
+// *regexp.Regexp in .Result. flags are any of the flag letters i, m, s and U that regexp understands,
+// eg "i" to ignore case. A pattern that doesn't compile is an error at the start of the literal. It
+// has no effect on other string literals.
+func CompileRegexp(flags string) StringOption {
+	for _, flag := range flags {
+		if !strings.ContainsRune("imsU", flag) {
+			panic(fmt.Errorf("%c is not a regexp flag", flag))
+		}
+	}
+	return func(o *stringOptions) {
+		o.compile = true
+		o.compileFlags = flags
+	}
+}
+
+// RegexpFlags lets the regexp literals be followed by any of the flag letters in allowed, eg "gimsx"
+// for /a+/gi, which are returned in a Result of their own as the last of .Child. The flags end at the
+// first letter that isn't allowed, which is left for whatever comes next. With CompileRegexp the
+// flags that regexp understands, i, m, s and U, are used when compiling the pattern.
+func RegexpFlags(allowed string) StringOption {
+	return func(o *stringOptions) {
+		o.flags = allowed
+	}
+}
+
+// regexpFlags reads the flags allowed by the RegexpFlags option at the current position
+func regexpFlags(ps *State, node *Result, allowed string) Result {
+	start := ps.Pos
+	runes := ps.Runes()
+	for !runes.Done() {
+		if r, _ := runes.Peek(); !strings.ContainsRune(allowed, r) {
+			break
+		}
+		runes.Next()
+	}
+	ps.Pos = runes.Pos()
+	return Result{Token: ps.Input[start:ps.Pos], Input: node.Input, Start: start, End: ps.Pos}
+}
+
+// compileRegexp compiles the pattern of the regexp literal that starts at startpos into its .Result,
+// when the CompileRegexp option asks for it. Any of the literal's flags that regexp understands are
+// added to flags.
+func compileRegexp(ps *State, node *Result, startpos int, flags string, literalFlags string) bool {
+	for _, flag := range literalFlags {
+		if strings.ContainsRune("imsU", flag) && !strings.ContainsRune(flags, flag) {
+			flags += string(flag)
+		}
+	}
+	pattern := node.Token
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		expected := "valid regexp"
+		if syntaxErr, ok := err.(*syntax.Error); ok {
+			expected += " (" + string(syntaxErr.Code) + ": `" + syntaxErr.Expr + "`)"
+		}
+		ps.errorAt(startpos, expected)
+		ps.Pos = startpos
+		return false
+	}
+	node.Result = re
+	return true
+}
+
+// RecoverEscapes replaces an invalid escape sequence, eg \u12 or \uZZZZ, with U+FFFD instead of failing
+// the whole string, and carries on after the escape character and the one following it. Each recovered
+// escape is added to .Child with the skipped text in .Token and the *Error it would have caused in
+// .Result.
+func RecoverEscapes() StringOption {
+	return func(o *stringOptions) {
+		o.recoverEscapes = true
+	}
+}
+
+// NonASCIIBytes lets a ByteStringLit contain characters outside of ASCII, which are encoded as UTF-8.
+func NonASCIIBytes() StringOption {
+	return func(o *stringOptions) {
+		o.nonASCII = true
+	}
+}
+
+func newStringOptions(opts []StringOption) stringOptions {
+	var o stringOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// kind returns what the string is called in error messages
+func (o stringOptions) kind() string {
+	if o.literal != "" {
+		return o.literal
+	}
+	return "string literal"
+}
+
+// closing returns the sequence that ends the string, for use in error messages
+func (o stringOptions) closing(closer rune) string {
+	if o.terminator != "" {
+		return o.terminator
+	}
+	return string(closer)
+}
+
+func stringImpl(ps *State, node *Result, closer rune, escapes map[rune]rune, opts stringOptions) bool {
 	var end = node.Start
+	if opts.recordEscapes || opts.recoverEscapes {
+		node.Child = nil
+	}
+	if opts.countEscapes {
+		node.Escapes = 0
+	}
 
 	inputLen := len(ps.Input)
 	var buf *bytes.Buffer
+	defer func() {
+		if buf != nil {
+			releaseStringBuffer(buf)
+		}
+	}()
+
+	escape := '\\'
+	if opts.escape != 0 {
+		escape = opts.escape
+	}
+	depth := 0
+	maxNesting := opts.maxNesting
+	if maxNesting <= 0 {
+		maxNesting = defaultMaxNesting
+	}
+
+	// counted adds n runes of content found at pos to the length, failing if it goes over the limit
+	length := 0
+	counted := func(pos, n int) bool {
+		length += n
+		if length > opts.maxLength {
+			ps.errorAt(pos, "at most "+strconv.Itoa(opts.maxLength)+" characters")
+			return false
+		}
+		return true
+	}
+
+	// the opening quote is just before the string, and is as long as the terminator if there is one
+	opening := node.Start - len(opts.terminator)
+	if opts.terminator == "" {
+		_, size := utf8.DecodeLastRuneInString(ps.Input[:node.Start])
+		opening = node.Start - size
+	}
+
+	// scanned counts the runes of input up to scannedTo, for MaxScan
+	scanned, scannedTo := 0, node.Start
+
+	// folding is set while skipping a run of whitespace that has been written as a single space
+	folding := false
 
 	for end < inputLen {
 		current, size := utf8.DecodeRuneInString(ps.Input[end:])
+		wasFolding := folding
+		folding = false
+		if opts.maxScan > 0 {
+			scanned += utf8.RuneCountInString(ps.Input[scannedTo:end])
+			scannedTo = end
+			// the closer doesn't count, but anything else must fit in the limit
+			closing := current == closer && depth == 0
+			if scanned > opts.maxScan || (scanned == opts.maxScan && !closing) {
+				ps.errorAt(end, opts.closing(closer)+" within "+strconv.Itoa(opts.maxScan)+" characters")
+				return false
+			}
+		}
+
 		switch current {
-		case '\\':
+		case escape:
 			if end+size >= inputLen {
-				ps.ErrorHere(string(closer))
+				ps.errorUnterminated(opts.kind(), opts.closing(closer), opening)
+				return false
+			}
+
+			if buf == nil {
+				buf = newStringBuffer(ps, node.Start, end, closer)
+			} else {
+				reserveStringBuffer(ps, buf, end, closer)
+			}
+
+			escapeStart, bufStart := end, buf.Len()
+			var ok bool
+			end, ok = stringEscape(ps, buf, end, escape, closer, escapes, opts)
+			if !ok {
+				if !opts.recoverEscapes {
+					return false
+				}
+				invalid := ps.Error
+				ps.Recover()
+				_, next := utf8.DecodeRuneInString(ps.Input[escapeStart+size:])
+				end = escapeStart + size + next
+				buf.WriteRune(utf8.RuneError)
+				if opts.maxLength > 0 && !counted(escapeStart, 1) {
+					return false
+				}
+				node.Child = append(node.Child, Result{
+					Token:  ps.Input[escapeStart:end],
+					Result: &invalid,
+					Input:  node.Input,
+					Start:  escapeStart,
+					End:    end,
+				})
+				continue
+			}
+			if opts.maxLength > 0 && !counted(escapeStart, utf8.RuneCount(buf.Bytes()[bufStart:])) {
+				return false
+			}
+			if opts.countEscapes {
+				node.Escapes++
+			}
+			if opts.recordEscapes {
+				node.Child = append(node.Child, Result{
+					Token: string(buf.Bytes()[bufStart:]),
+					Input: node.Input,
+					Start: escapeStart,
+					End:   end,
+				})
+			}
+		case closer:
+			if depth > 0 {
+				if opts.maxLength > 0 && !counted(end, 1) {
+					return false
+				}
+				depth--
+				end += size
+				if buf != nil {
+					buf.WriteRune(current)
+				}
+				continue
+			}
+			if opts.doubledQuotes {
+				if next, _ := utf8.DecodeRuneInString(ps.Input[end+size:]); next == closer {
+					if opts.maxLength > 0 && !counted(end, 1) {
+						return false
+					}
+					if buf == nil {
+						buf = newStringBuffer(ps, node.Start, end, closer)
+					}
+					buf.WriteRune(closer)
+					end += size * 2
+					continue
+				}
+			}
+			if opts.terminator != "" {
+				if !strings.HasPrefix(ps.Input[end:], opts.terminator) {
+					if opts.maxLength > 0 && !counted(end, 1) {
+						return false
+					}
+					end += size
+					if buf != nil {
+						buf.WriteRune(current)
+					}
+					continue
+				}
+				size = len(opts.terminator)
+			}
+			if buf == nil {
+				node.Token = ps.Input[node.Start:end]
+			} else {
+				node.Token = buf.String()
+			}
+			ps.Pos = end + size
+			node.End = ps.Pos
+			return true
+		default:
+			if opts.noControlChars && current < 0x20 {
+				ps.errorAt(end, "escaped control character")
+				return false
+			}
+			if opts.foldWhitespace && unicode.IsSpace(current) {
+				if buf == nil {
+					buf = newStringBuffer(ps, node.Start, end, closer)
+				}
+				if !wasFolding {
+					if opts.maxLength > 0 && !counted(end, 1) {
+						return false
+					}
+					buf.WriteByte(' ')
+				}
+				folding = true
+				end += size
+				continue
+			}
+			if opts.bytes && !opts.nonASCII && current >= utf8.RuneSelf {
+				ps.errorAt(end, "ASCII character")
+				return false
+			}
+			if opts.opener != 0 && current == opts.opener {
+				depth++
+				if depth > maxNesting {
+					ps.errorAt(end, "at most "+strconv.Itoa(maxNesting)+" nested "+string(current))
+					return false
+				}
+			}
+			if opts.maxLength > 0 && !counted(end, 1) {
 				return false
 			}
+			end += size
+			if buf != nil {
+				buf.WriteRune(current)
+			}
+		}
+	}
+	ps.errorUnterminated(opts.kind(), opts.closing(closer), opening)
+	return false
+}
+
+// stringBuffers holds the buffers used to decode escapes, which can be reused as soon as the decoded
+// string has been copied out of them
+var stringBuffers = sync.Pool{
+	New: func() interface{} { return &bytes.Buffer{} },
+}
+
+// releaseStringBuffer returns buf to the pool, unless it has grown too large to be worth keeping
+func releaseStringBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= 64*1024 {
+		stringBuffers.Put(buf)
+	}
+}
+
+// newStringBuffer starts the buffer for a string that has escapes to decode, holding the text from
+// start to end. Decoding rarely makes a string longer, so it is grown up front to fit everything up to
+// the next closer, which is usually the whole string, to avoid copying long strings as they grow.
+func newStringBuffer(ps *State, start, end int, closer rune) *bytes.Buffer {
+	buf := stringBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	buf.Grow(end - start + untilCloser(ps, end, closer))
+	buf.WriteString(ps.Input[start:end])
+	return buf
+}
+
+// reserveStringBuffer grows buf, when it is nearly full, to fit the input from end up to the next
+// closer. The first guess falls short when the closer is escaped, or escapes expand to more than they
+// replace, and this keeps the number of times the decoded string is copied proportional to the number
+// of times the guess was wrong rather than to its length.
+func reserveStringBuffer(ps *State, buf *bytes.Buffer, end int, closer rune) {
+	if buf.Cap()-buf.Len() < utf8.UTFMax*2 {
+		buf.Grow(untilCloser(ps, end, closer) + utf8.UTFMax*2)
+	}
+}
+
+// untilCloser returns the number of bytes from end to the next closer, or to the end of the input
+func untilCloser(ps *State, end int, closer rune) int {
+	rest := strings.IndexRune(ps.Input[end:], closer)
+	if rest < 0 {
+		rest = len(ps.Input) - end
+	}
+	return rest
+}
+
+// stringEscape decodes the escape sequence starting at end into buf, returning the position after it
+func stringEscape(ps *State, buf *bytes.Buffer, end int, escape rune, closer rune, escapes map[rune]rune, opts stringOptions) (int, bool) {
+	current, size := utf8.DecodeRuneInString(ps.Input[end:])
+	if opts.percent {
+		r, ok := hexEscape(ps, end+size, 2)
+		if !ok {
+			return end, false
+		}
+		buf.WriteByte(byte(r))
+		return end + size + 2, true
+	}
+
+	c, s := utf8.DecodeRuneInString(ps.Input[end+size:])
+	if opts.escapeFunc != nil {
+		startpos := ps.Pos
+		ps.Pos = end + size
+		replacement, n, ok := opts.escapeFunc(ps, c)
+		ps.Pos = startpos
+		if ps.Errored() {
+			return end, false
+		}
+		if ok {
+			buf.WriteString(replacement)
+			next := end + size
+			for ; n > 0 && next < len(ps.Input); n-- {
+				_, w := utf8.DecodeRuneInString(ps.Input[next:])
+				next += w
+			}
+			return next, true
+		}
+	}
+
+	for _, name := range opts.namedEscapes {
+		if strings.HasPrefix(ps.Input[end+size:], name) {
+			buf.WriteString(opts.names[name])
+			return end + size + len(name), true
+		}
+	}
+
+	if opts.verbatim {
+		if replacement, ok := escapes[c]; ok {
+			buf.WriteRune(replacement)
+		} else if c == closer && regexp.QuoteMeta(string(c)) == string(c) {
+			buf.WriteRune(c)
+		} else {
+			// the closer is left escaped when the regexp engine would otherwise give it a meaning
+			buf.WriteRune(current)
+			buf.WriteRune(c)
+		}
+		return end + size + s, true
+	}
+
+	letter := c
+	if opts.bytes && (c == 'u' || c == 'U') {
+		// byte strings have no unicode escapes, so these are unknown like any other letter
+		letter = 0
+	}
+	switch letter {
+	case 'u':
+		if strings.HasPrefix(ps.Input[end+size+s:], "{") {
+			r, n, ok := bracedHexEscape(ps, end+size+s)
+			if !ok {
+				return end, false
+			}
+			buf.WriteRune(r)
+			return end + size + s + n, true
+		}
+
+		r, ok := hexEscape(ps, end+size+s, 4)
+		if !ok {
+			return end, false
+		}
+		next := end + size + s + 4
+		if utf16.IsSurrogate(r) {
+			r, next, ok = surrogatePair(ps, r, end, next, escape)
+			if !ok {
+				return end, false
+			}
+		}
+		buf.WriteRune(r)
+		return next, true
+	case 'U':
+		r, ok := hexEscape(ps, end+size+s, 8)
+		if !ok {
+			return end, false
+		}
+		if !utf8.ValidRune(r) {
+			ps.errorAt(end+size+s, "valid unicode code point")
+			return end, false
+		}
+		buf.WriteRune(r)
+		return end + size + s + 8, true
+	case 'x':
+		r, ok := hexEscape(ps, end+size+s, 2)
+		if !ok {
+			return end, false
+		}
+		buf.WriteByte(byte(r))
+		return end + size + s + 2, true
+	default:
+		if opts.lineContinuation && c == '\n' {
+			return end + size + s, true
+		}
+		if opts.lineContinuation && c == '\r' && strings.HasPrefix(ps.Input[end+size+s:], "\n") {
+			return end + size + s + 1, true
+		}
+
+		if opts.octal && c >= '0' && c <= '7' {
+			b, n, ok := octalEscape(ps, end+size)
+			if !ok {
+				return end, false
+			}
+			buf.WriteByte(b)
+			return end + size + n, true
+		}
+
+		if c == closer {
+			buf.WriteRune(c)
+		} else {
+			replacement, ok := escapes[c]
+			if ok {
+				buf.WriteRune(replacement)
+			} else if opts.strict && (c == escape || c == '/') {
+				buf.WriteRune(c)
+			} else if opts.strict {
+				ps.errorAt(end, validEscapes(escape, closer, escapes, opts))
+				return end, false
+			} else {
+				// write both the slash and the following character
+				buf.WriteRune(current)
+				buf.WriteRune(c)
+			}
+		}
+		return end + size + s, true
+	}
+}
+
+// hexEscape decodes the given number of hex digits starting at pos, as found after \u, \U or \x
+func hexEscape(ps *State, pos int, digits int) (rune, bool) {
+	if pos+digits > len(ps.Input) {
+		ps.errorAt(pos, "[a-f0-9]{"+strconv.Itoa(digits)+"}")
+		return 0, false
+	}
+
+	r, ok := unhex(ps.Input[pos : pos+digits])
+	if !ok {
+		ps.errorAt(pos, "[a-f0-9]")
+		return 0, false
+	}
+	return r, true
+}
+
+// surrogatePair combines the UTF-16 surrogate r from the \u escape at pos with the low surrogate
+// escape that must follow it at next, as JSON writes characters outside the basic multilingual plane.
+// It returns the combined rune and the position after the second escape.
+func surrogatePair(ps *State, r rune, pos, next int, escape rune) (rune, int, bool) {
+	prefix := string(escape) + "u"
+	digits := next + len(prefix)
+	if r < 0xDC00 && strings.HasPrefix(ps.Input[next:], prefix) && digits+4 <= len(ps.Input) {
+		low, ok := unhex(ps.Input[digits : digits+4])
+		if ok && 0xDC00 <= low && low <= 0xDFFF {
+			return utf16.DecodeRune(r, low), digits + 4, true
+		}
+	}
+	ps.errorAt(pos, "surrogate pair")
+	return 0, next, false
+}
+
+// bracedHexEscape decodes a \u{1F600} style escape of one to six hex digits, which may be separated by
+// single underscores as in NumberLit, eg \u{1_F600}, where pos is the position of the opening brace. It
+// returns the rune and the length of the braces and digits.
+func bracedHexEscape(ps *State, pos int) (rune, int, bool) {
+	digits := pos + 1
+	end, errPos := scanDigits(ps.Input, digits, 16)
+	if errPos < 0 && end == digits {
+		errPos = end
+	}
+	if errPos >= 0 {
+		ps.errorAt(errPos, "[a-f0-9]")
+		return 0, 0, false
+	}
+
+	// stop at the seventh digit so that it is reported as a missing }
+	count := 0
+	for i := digits; i < end; i++ {
+		if ps.Input[i] != '_' {
+			count++
+		}
+		if count > 6 {
+			end = i
+			break
+		}
+	}
+	if end >= len(ps.Input) || ps.Input[end] != '}' {
+		ps.errorAt(end, "}")
+		return 0, 0, false
+	}
+
+	r, _ := unhex(stripUnderscores(ps.Input[digits:end]))
+	if !utf8.ValidRune(r) {
+		ps.errorAt(digits, "valid unicode code point")
+		return 0, 0, false
+	}
+	return r, end + 1 - pos, true
+}
+
+// validEscapes describes the escape sequences stringImpl accepts, for use in error messages
+func validEscapes(escape rune, closer rune, escapes map[rune]rune, opts stringOptions) string {
+	chars := []string{string(closer), string(escape), "/"}
+	for c := range escapes {
+		if c != escape && c != '/' {
+			chars = append(chars, string(c))
+		}
+	}
+	sort.Strings(chars[3:])
+	if !opts.bytes {
+		chars = append(chars, "u", "U")
+	}
+	chars = append(chars, "x")
+	if opts.octal {
+		chars = append(chars, "[0-7]")
+	}
+	if opts.lineContinuation {
+		chars = append(chars, "newline")
+	}
+	return "escape " + string(escape) + strings.Join(chars, " "+string(escape))
+}
+
+// octalEscape decodes up to three octal digits starting at pos, returning the byte and the number of
+// digits consumed
+func octalEscape(ps *State, pos int) (byte, int, bool) {
+	v, n := 0, 0
+	for n < 3 && pos+n < len(ps.Input) && ps.Input[pos+n] >= '0' && ps.Input[pos+n] <= '7' {
+		v = v*8 + int(ps.Input[pos+n]-'0')
+		n++
+	}
+	if v > 255 {
+		ps.errorAt(pos, "octal escape up to \\377")
+		return 0, 0, false
+	}
+	return byte(v), n, true
+}
+
+// StringLit matches a quoted string and returns it in .Token. It may contain:
+//  - unicode
+//  - escaped characters, eg \", \n, \t
+//  - unicode sequences, eg \uBEEF, \u{1F600} or \U0001F600
+//  - UTF-16 surrogate pairs, eg \uD83D\uDE00 for 😀
+//  - hex bytes, eg \x41
+// allowedQuotes is the list of allowed quote characters; both the
+// opening and closing quotes will be the same character from this
+// string.
+//
+// As a special case, an empty allowedQuotes lets the string be quoted by
+// whatever character it starts with, eg |hello| or #hello#, except for
+// digits and whitespace.
+//
+// The quote that was matched is returned in .Opener and .Closer, and the
+// same StringOptions as for CustomStringLiteral may be given, eg
+// KeepQuotes to also return the string as written in .Raw.
+func StringLit(allowedQuotes string, opts ...StringOption) Parser {
+	options := newStringOptions(opts)
+	return NewParser("string literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		startpos := ps.Pos
+
+		if allowedQuotes == "" {
+			opener, size := decodeDelimiter(ps)
+			if ps.Errored() {
+				return
+			}
+			if size == 0 || unicode.IsDigit(opener) || unicode.IsSpace(opener) {
+				ps.ErrorHere("string delimiter")
+				return
+			}
+			node.Start = ps.Pos + size
+			stringLit(ps, node, opener, startpos, options)
+			return
+		}
+
+		opener, size := utf8.DecodeRuneInString(ps.Input[ps.Pos:])
+		if !stringContainsRune(allowedQuotes, opener) {
+			ps.ErrorHere(allowedQuotes)
+			return
+		}
+		node.Start = ps.Pos + size
+		stringLit(ps, node, opener, startpos, options)
+	})
+}
+
+// stringLit finishes a StringLit once its opening quote at startpos has been found
+func stringLit(ps *State, node *Result, quote rune, startpos int, opts stringOptions) {
+	if !stringImpl(ps, node, quote, _Escapes, opts) {
+		return
+	}
+	node.Opener = quote
+	node.Closer = quote
+	if opts.keepQuotes {
+		node.Raw = ps.Input[startpos:ps.Pos]
+	}
+}
+
+// AnyStringLit matches a string in single quotes, double quotes or backticks, all with the same
+// escapes as StringLit. The quote that was used is returned in .Opener and .Closer.
+func AnyStringLit(opts ...StringOption) Parser {
+	return StringLit("'\"`", opts...)
+}
+
+// ByteStringLit matches a byte string such as b"hello\xFF" or b'abc', and returns it as a []byte in
+// .Result as well as in .Token. Escapes are handled as in StringLit, except that \x always stands for
+// a single byte and there are no unicode escapes. The string may only contain ASCII characters unless
+// the NonASCIIBytes option is given, and the other StringOptions, eg OctalEscapes, may also be used.
+func ByteStringLit(opts ...StringOption) Parser {
+	options := newStringOptions(opts)
+	options.bytes = true
+	return NewParser("byte string literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		input := ps.Get()
+		if len(input) < 2 || (input[0] != 'b' && input[0] != 'B') || (input[1] != '"' && input[1] != '\'') {
+			ps.ErrorHere(`b"`)
+			return
+		}
+		startpos := ps.Pos
+		node.Start = ps.Pos + 2
+		if !stringImpl(ps, node, rune(input[1]), _Escapes, options) {
+			return
+		}
+		node.Result = []byte(node.Token)
+		if options.keepQuotes {
+			node.Raw = ps.Input[startpos:ps.Pos]
+		}
+	})
+}
+
+// ConcatStringLits matches one or more string literals as for StringLit separated only by whitespace,
+// eg "foo" "bar", and joins them together the way C does, returning foobar in .Token. Each literal is
+// also returned in .Child, with its own position.
+func ConcatStringLits(allowedQuotes string) Parser {
+	str := StringLit(allowedQuotes)
+	return NewParser("string literals", func(ps *State, node *Result) {
+		first := Result{Input: node.Input}
+		str(ps, &first)
+		if ps.Errored() {
+			return
+		}
+		node.Child = append(ps.Children(4)[:0], first)
+
+		for {
+			next := Result{Input: node.Input}
+			snap := ps.Snapshot()
+			str(ps, &next)
+			if ps.Errored() {
+				ps.Restore(snap)
+				break
+			}
+			node.Child = append(node.Child, next)
+		}
+
+		if len(node.Child) == 1 {
+			node.Token = first.Token
+		} else {
+			joined := &bytes.Buffer{}
+			for _, child := range node.Child {
+				joined.WriteString(child.Token)
+			}
+			node.Token = joined.String()
+		}
+		node.Start = first.Start
+		node.End = ps.Pos
+	})
+}
+
+// RawStringLit matches a quoted string and returns it in .Token exactly as
+// written, without processing any escapes, like Go's backtick strings.
+// allowedQuotes is the list of allowed quote characters; both the
+// opening and closing quotes will be the same character from this
+// string
+func RawStringLit(allowedQuotes string) Parser {
+	return NewParser("raw string literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		opener, size := utf8.DecodeRuneInString(ps.Input[ps.Pos:])
+		if !stringContainsRune(allowedQuotes, opener) {
+			ps.ErrorHere(allowedQuotes)
+			return
+		}
+		node.Start = ps.Pos + size
+		length := strings.IndexRune(ps.Input[node.Start:], opener)
+		if length < 0 {
+			ps.errorUnterminated("raw string literal", string(opener), ps.Pos)
+			return
+		}
+		node.Token = ps.Input[node.Start : node.Start+length]
+		ps.Pos = node.Start + length + size
+		node.End = ps.Pos
+	})
+}
+
+// CharLit matches a single character between quotes, eg 'a', '\n' or
+// '\u0041', and returns it as a rune in .Result. Escapes are handled as
+// in StringLit, and it is an error for the quotes to hold anything other
+// than exactly one character. A \x escape is a single byte, so '\xff' is
+// U+00FF, while a raw byte that isn't valid UTF-8 is an error.
+func CharLit(quote rune) Parser {
+	return NewParser("char literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		opener, size := utf8.DecodeRuneInString(ps.Input[ps.Pos:])
+		if opener != quote {
+			ps.ErrorHere(string(quote))
+			return
+		}
+		start := ps.Pos
+		node.Start = ps.Pos + size
+		if !stringImpl(ps, node, quote, _Escapes, stringOptions{literal: "char literal"}) {
+			return
+		}
+
+		if strings.HasPrefix(ps.Input[node.Start:], `\x`) {
+			// a \x escape is a single byte, which is its own value rather than part of a UTF-8 sequence
+			if len(node.Token) != 1 {
+				ps.errorAt(node.Start, "single character")
+				ps.Pos = start
+				return
+			}
+			node.Result = rune(node.Token[0])
+			return
+		}
+
+		r, size := utf8.DecodeRuneInString(node.Token)
+		if node.Token == "" || size != len(node.Token) {
+			ps.errorAt(node.Start, "single character")
+			ps.Pos = start
+			return
+		}
+		if r == utf8.RuneError && size == 1 {
+			ps.errorAt(node.Start, "valid UTF-8")
+			ps.Pos = start
+			return
+		}
+		node.Result = r
+	})
+}
+
+// TripleQuotedStringLit matches a string opened and closed by three
+// quote characters, eg """hello""", and returns it in .Token. Single
+// quote characters and newlines inside the string are kept as they
+// are, and escapes are handled as in StringLit.
+func TripleQuotedStringLit(quote rune) Parser {
+	triple := strings.Repeat(string(quote), 3)
+	opts := stringOptions{terminator: triple}
+	return NewParser("triple quoted string literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		if !strings.HasPrefix(ps.Get(), triple) {
+			ps.ErrorHere(triple)
+			return
+		}
+		node.Start = ps.Pos + len(triple)
+		stringImpl(ps, node, quote, _Escapes, opts)
+	})
+}
+
+// HeredocLit matches a shell style heredoc and returns its body in .Token.
+// The heredoc starts with << and an identifier at the end of a line, and
+// the body is every following line up to one that contains only that
+// identifier, eg:
+//  <<EOF
+//  hello
+//  EOF
+// With <<- the terminating line may also be indented with tabs.
+func HeredocLit() Parser {
+	return NewParser("heredoc literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		if !strings.HasPrefix(ps.Get(), "<<") {
+			ps.ErrorHere("<<")
+			return
+		}
+		end := ps.Pos + 2
+		indented := end < len(ps.Input) && ps.Input[end] == '-'
+		if indented {
+			end++
+		}
+
+		identStart := end
+		for end < len(ps.Input) && isIdentByte(ps.Input[end]) {
+			end++
+		}
+		ident := ps.Input[identStart:end]
+		if ident == "" {
+			ps.errorAt(identStart, "heredoc identifier")
+			return
+		}
+
+		if strings.HasPrefix(ps.Input[end:], "\r\n") {
+			end += 2
+		} else if strings.HasPrefix(ps.Input[end:], "\n") {
+			end++
+		} else {
+			ps.errorAt(end, "newline")
+			return
+		}
+
+		bodyStart := end
+		for {
+			line := ps.Input[end:]
+			lineEnd := strings.IndexByte(line, '\n')
+			if lineEnd >= 0 {
+				line = line[:lineEnd]
+			}
+
+			terminator := strings.TrimSuffix(line, "\r")
+			if indented {
+				terminator = strings.TrimLeft(terminator, "\t")
+			}
+			if terminator == ident {
+				node.Start = bodyStart
+				node.Token = ps.Input[bodyStart:end]
+				ps.Pos = end + len(line)
+				node.End = ps.Pos
+				return
+			}
+
+			if lineEnd < 0 {
+				break
+			}
+			end += lineEnd + 1
+		}
+		ps.errorUnterminated("heredoc", ident, ps.Pos)
+	})
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// UnicodeStringLiteral matches a quoted string and returns it in .Token. It may contain:
+//  - unicode
+//  - escaped characters, eg \", \n, \t
+//  - unicode sequences, eg \uBEEF, \u{1F600} or \U0001F600
+//  - UTF-16 surrogate pairs, eg \uD83D\uDE00 for 😀
+//  - hex bytes, eg \x41
+// The opening and closing quote character may be any matched pair of
+// unicode characters from the Pi/Pf categories, or from the Ps/Pe
+// categories, plus angle brackets, or if they may be a punctuation
+// character, as long as they are the same punctuation character.
+func UnicodeStringLiteral() Parser {
+	return CustomStringLiteral(IsValidRegexpDelimiter, _Escapes)
+}
+
+// CustomStringLiteral matches a quoted string and returns it in .Token. It may contain:
+//  - unicode
+//  - escaped characters, eg \", \n, \t
+//  - unicode sequences, eg \uBEEF, \u{1F600} or \U0001F600
+//  - UTF-16 surrogate pairs, eg \uD83D\uDE00 for 😀
+//  - hex bytes, eg \x41
+// The opening and closing quotes are validated by the isValid
+// function you pass in. This function should return true if its
+// argument is a valid opening quote character, plus the correct
+// closing quote character that ends the string. See
+// IsValidRegexpDelimiter.
+//
+// The quote characters that were matched are returned in .Opener and
+// .Closer.
+//
+// The only valid escape characters are those defined in the escapes
+// argument, plus one for the closer returned by isValid. StringOptions
+// change this and more:
+//  - OctalEscapes, LineContinuations, NamedEscapes and EscapeFunc add
+//    further escapes
+//  - StrictEscapes turns any other escape into an error, and
+//    RecoverEscapes replaces invalid escapes rather than failing
+//  - EscapeWith replaces the backslash, and PercentEscapes decodes URL
+//    style %41 escapes instead
+//  - DoubledQuotes allows the closer to be escaped by doubling it
+//  - NoControlChars rejects raw newlines and other control characters,
+//    and FoldWhitespace collapses runs of whitespace to a single space
+//  - RecordEscapes reports where each escape was found, and CountEscapes
+//    how many there were
+//  - KeepQuotes returns the literal as written
+//  - NestedDelimiters balances brackets, up to MaxNesting deep
+//  - MaxLength limits the length of the string, and MaxScan how much input
+//    may be read looking for the closer
+func CustomStringLiteral(isValid func(rune) (bool, rune), escapes map[rune]rune, opts ...StringOption) Parser {
+	options := newStringOptions(opts)
+	return NewParser("string literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		opener, size := decodeDelimiter(ps)
+		if ps.Errored() {
+			return
+		}
+		valid, closer := isValid(opener)
+		if !valid {
+			ps.ErrorHere("string delimiter")
+			return
+		}
+		startpos := ps.Pos
+		node.Start = ps.Pos + size
+		opts := options
+		if opts.nested && opener != closer {
+			opts.opener = opener
+		}
+		if stringImpl(ps, node, closer, escapes, opts) {
+			node.Opener = opener
+			node.Closer = closer
+			if opts.keepQuotes {
+				node.Raw = ps.Input[startpos:ps.Pos]
+			}
+		}
+	})
+}
+
+// decodeDelimiter returns the delimiter at the current position, raising an error if it is
+// not valid UTF-8 so that the replacement character isn't mistaken for a delimiter.
+func decodeDelimiter(ps *State) (rune, int) {
+	runes := ps.Runes()
+	r, size, _ := runes.Next()
+	if !runes.Valid() {
+		ps.ErrorHere("valid UTF-8")
+	}
+	return r, size
+}
+
+// UnicodeRegexpMatchLiteral matches a regexp such as /a+/ or «a+» and returns the pattern in .Token, as
+// for CustomRegexpMatchLiteral with IsValidRegexpDelimiter and no extra escapes.
+func UnicodeRegexpMatchLiteral(opts ...StringOption) Parser {
+	return CustomRegexpMatchLiteral(IsValidRegexpDelimiter, nil, opts...)
+}
+
+// CustomRegexpMatchLiteral matches a regexp between delimiters accepted by isValid, and returns the
+// pattern in .Token and the delimiters in .Opener and .Closer. Escapes in the pattern are left for the
+// regexp engine, so \d and \n stay as they are, except for those in escapes and an escaped closer,
+// which is written without its backslash unless that would give it a special meaning, eg /a\/b/ is
+// a/b, but .a\.b. keeps the backslash, as a . on its own would match any character.
+//
+// With the CompileRegexp option the compiled pattern is returned in .Result, and with RegexpFlags
+// the flags after the literal are returned as its last child.
+func CustomRegexpMatchLiteral(isValid func(rune) (bool, rune), escapes map[rune]rune, opts ...StringOption) Parser {
+	options := newStringOptions(opts)
+	options.verbatim = true
+	options.literal = "regexp literal"
+	return NewParser("regexp match literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		startpos := ps.Pos
+
+		opener, size := decodeDelimiter(ps)
+		if ps.Errored() {
+			return
+		}
+		valid, closer := isValid(opener)
+		if !valid {
+			ps.ErrorHere("regexp delimiter")
+			return
+		}
+		node.Start = ps.Pos + size
+		if !stringImpl(ps, node, closer, escapes, options) {
+			return
+		}
+		var flags Result
+		if options.flags != "" {
+			flags = regexpFlags(ps, node, options.flags)
+		}
+		if options.compile && !compileRegexp(ps, node, startpos, options.compileFlags, flags.Token) {
+			return
+		}
+		if options.flags != "" {
+			if !options.recordEscapes && !options.recoverEscapes {
+				node.Child = ps.Children(1)[:0]
+			}
+			node.Child = append(node.Child, flags)
+			node.End = ps.Pos
+		}
+		node.Opener = opener
+		node.Closer = closer
+	})
+}
+
+func UnicodeRegexpReplaceLiteral(opts ...StringOption) Parser {
+	return CustomRegexpReplaceLiteral(IsValidRegexpDelimiter, nil, opts...)
+}
+
+// RegexpReplaceLiteral matches a regexp replacement where all three delimiters are delim, eg %a%b% for
+// a delim of %, and returns the pattern and replacement in .Child[0] and .Child[1].
+func RegexpReplaceLiteral(delim rune) Parser {
+	return CustomRegexpReplaceLiteral(func(r rune) (bool, rune) {
+		return r == delim, delim
+	}, nil)
+}
+
+// CustomRegexpReplaceLiteral matches a regexp replacement such as /pat/repl/ or {pat}{repl} and
+// returns the pattern and replacement in .Child[0] and .Child[1], each with the delimiters they were
+// written with in .Opener and .Closer, and those of the pattern on the node itself. When the
+// delimiters are brackets the replacement must use the same brackets as the pattern unless the
+// MixedDelimiters option is given. Escapes in the pattern are left for the regexp engine as for
+// CustomRegexpMatchLiteral, while those in the replacement are decoded as in StringLit. With the
+// CompileRegexp option the compiled pattern is returned in the .Result of .Child[0], and with
+// RegexpFlags the flags after the literal are returned in .Child[2].
+func CustomRegexpReplaceLiteral(isValid func(rune) (bool, rune), escapes map[rune]rune, opts ...StringOption) Parser {
+	options := newStringOptions(opts)
+	options.literal = "regexp literal"
+	return NewParser("regexp replace literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		child1 := node.Clone()
+		opener, size := decodeDelimiter(ps)
+		if ps.Errored() {
+			return
+		}
+		valid, closer := isValid(opener)
+		if !valid {
+			ps.ErrorHere("regexp delimiter")
+			return
+		}
+		startpos := ps.Pos
+		child1.Start = ps.Pos + size
+
+		patternOptions := options
+		patternOptions.verbatim = true
+		if !stringImpl(ps, &child1, closer, escapes, patternOptions) {
+			return
+		}
+		child1.Opener = opener
+		child1.Closer = closer
+
+		child2 := node.Clone()
+		child2.Start = ps.Pos
+		if closer != opener {
+			first := opener
+			opener, size = decodeDelimiter(ps)
+			if ps.Errored() {
+				ps.Pos = startpos
+				return
+			}
+			if !options.mixedDelimiters && opener != first {
+				ps.ErrorHere(string(first))
+				ps.Pos = startpos
+				return
+			}
+			valid, closer = isValid(opener)
+			if !valid {
+				ps.ErrorHere("regexp delimiter")
+				ps.Pos = startpos
+				return
+			}
+			child2.Start = ps.Pos + size
+		}
+
+		if !stringImpl(ps, &child2, closer, _Escapes, options) {
+			ps.Pos = startpos
+			return
+		}
+
+		child2.Opener = opener
+		child2.Closer = closer
+
+		var flags Result
+		if options.flags != "" {
+			flags = regexpFlags(ps, node, options.flags)
+		}
+		if options.compile && !compileRegexp(ps, &child1, startpos, options.compileFlags, flags.Token) {
+			return
+		}
+
+		if options.flags == "" {
+			node.Child = ps.Children(2)
+		} else {
+			node.Child = ps.Children(3)
+			node.Child[2] = flags
+		}
+		node.Child[0], node.Child[1] = child1, child2
+		node.Opener = child1.Opener
+		node.Closer = child1.Closer
+		node.Start = child1.Start
+		node.End = ps.Pos
+	})
+}
+
+// IdentLit matches an identifier whose first character is in one of the startCats tables and whose
+// remaining characters are in one of the contCats tables, and returns it in .Token. For example,
+// identifiers made of letters, digits and underscores that don't start with a digit are
+//  IdentLit([]*unicode.RangeTable{unicode.L, unicode.Pc}, []*unicode.RangeTable{unicode.L, unicode.Nd, unicode.Pc})
+func IdentLit(startCats, contCats []*unicode.RangeTable) Parser {
+	return NewParser("identifier", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		runes := ps.Runes()
+		current, size, _ := runes.Next()
+		if size == 0 || !unicode.IsOneOf(startCats, current) {
+			ps.ErrorHere("identifier")
+			return
+		}
+		for !runes.Done() {
+			if current, _ := runes.Peek(); !unicode.IsOneOf(contCats, current) {
+				break
+			}
+			runes.Next()
+		}
+		end := runes.Pos()
+
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// QuotedIdentLit matches an identifier quoted with open and close, as SQL does for names that contain
+// spaces or are keywords, eg [My Column] or `order`, and returns the name in .Token. There are no
+// escapes except that the closer may be written twice to stand for itself, eg [a]]b] for a]b. The
+// quotes are returned in .Opener and .Closer.
+func QuotedIdentLit(open, close rune) Parser {
+	closer := string(close)
+	return NewParser("quoted identifier", func(ps *State, node *Result) {
+		ps.WS(ps)
+		if !strings.HasPrefix(ps.Get(), string(open)) {
+			ps.ErrorHere(string(open))
+			return
+		}
+		start := ps.Pos + utf8.RuneLen(open)
+
+		var name strings.Builder
+		end := start
+		for {
+			i := strings.Index(ps.Input[end:], closer)
+			if i < 0 {
+				ps.errorUnterminated("quoted identifier", closer, ps.Pos)
+				return
+			}
+			name.WriteString(ps.Input[end : end+i])
+			end += i + len(closer)
+			if !strings.HasPrefix(ps.Input[end:], closer) {
+				break
+			}
+			name.WriteString(closer)
+			end += len(closer)
+		}
+		if name.Len() == 0 {
+			ps.errorAt(start, "identifier")
+			return
+		}
+
+		node.Token = name.String()
+		node.Opener = open
+		node.Closer = close
+		node.Start = start
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// BoolLit matches any of the truthy or falsy words, eg true, yes or on and false, no or off, ignoring
+// case, and returns true or false in .Result and the word as written in .Token. A word only matches
+// when it isn't followed by more letters or digits, so trueish is not true.
+func BoolLit(truthy, falsy []string) Parser {
+	expected := strings.Join(append(append([]string(nil), truthy...), falsy...), " or ")
+	return NewParser("bool literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		value := true
+		n := matchWord(ps.Get(), truthy)
+		if n < 0 {
+			value = false
+			n = matchWord(ps.Get(), falsy)
+		}
+		if n < 0 {
+			ps.ErrorHere(expected)
+			return
+		}
+		node.Result = value
+		node.Token = ps.Input[ps.Pos : ps.Pos+n]
+		node.Start = ps.Pos
+		node.End = ps.Pos + n
+		ps.Pos += n
+	})
+}
+
+// matchWord returns the length of the longest of words found at the start of s ignoring case, as long
+// as it isn't followed by more of a word, or -1 if there are none
+func matchWord(s string, words []string) int {
+	longest := -1
+	for _, word := range words {
+		if word == "" || len(word) <= longest || len(word) > len(s) || !strings.EqualFold(s[:len(word)], word) {
+			continue
+		}
+		if !wordBoundary(s[len(word):]) {
+			continue
+		}
+		longest = len(word)
+	}
+	return longest
+}
+
+// wordBoundary reports whether the rest of the input s doesn't continue the word before it
+func wordBoundary(s string) bool {
+	next, _ := utf8.DecodeRuneInString(s)
+	return !(next == '_' || unicode.IsLetter(next) || unicode.IsDigit(next))
+}
+
+// KeywordLit matches word ignoring case, eg SELECT, select or Select for a word of SELECT, as long as
+// it isn't followed by more letters or digits, and returns word as given in .Token.
+func KeywordLit(word string) Parser {
+	words := []string{word}
+	return NewParser(word, func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		n := matchWord(ps.Get(), words)
+		if n < 0 {
+			ps.ErrorHere(word)
+			return
+		}
+		node.Token = word
+		node.Start = ps.Pos
+		node.End = ps.Pos + n
+		ps.Pos += n
+	})
+}
+
+// NullLit matches keyword, eg null or nil, as long as it isn't followed by more letters or digits. It
+// leaves .Result nil and sets .Token to the keyword, so a match can be told apart from a result that was
+// never filled in.
+func NullLit(keyword string) Parser {
+	return NewParser(keyword, func(ps *State, node *Result) {
+		ps.WS(ps)
+
+		if !strings.HasPrefix(ps.Get(), keyword) || !wordBoundary(ps.Input[ps.Pos+len(keyword):]) {
+			ps.ErrorHere(keyword)
+			return
+		}
+		node.Result = nil
+		node.Token = keyword
+		node.Start = ps.Pos
+		node.End = ps.Pos + len(keyword)
+		ps.Pos += len(keyword)
+	})
+}
+
+// Sign matches an optional + or - and returns an int64 in .Result, -1 for a - and 1 otherwise, with
+// the sign itself, if any, in .Token. It never fails, so it can go in front of parsers that have no
+// sign of their own.
+func Sign() Parser {
+	return NewParser("sign", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, negative := scanSign(ps.Input, ps.Pos)
+		node.Result = int64(1)
+		if negative {
+			node.Result = int64(-1)
+		}
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// NumberLit matches a floating point or integer number and returns it as a int64 or float64 in .Result
+// and the exact text that was matched in .Token.
+// Integers may also be written in hexadecimal, octal or binary with a 0x, 0o or 0b prefix, floats
+// in hexadecimal with a binary exponent, eg 0x1.8p3, and single underscores may be used between
+// digits as separators, eg 1_000_000. Floats may leave out either the integer part or the fraction,
+// eg .5 or 5., but not both.
+//
+// NumberOptions change which numbers are accepted, eg NoPlusSign.
+func NumberLit(opts ...NumberOption) Parser {
+	options := numberOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return numberLit(options)
+}
+
+// NumberOption enables optional behaviour in NumberLit
+type NumberOption func(*numberOptions)
+
+type numberOptions struct {
+	// maxDigits limits the number of digits in the mantissa when it is above 0
+	maxDigits int
+	noPlus    bool
+	// pointNeedsDigit stops a . that isn't followed by a digit from being taken as a decimal point
+	pointNeedsDigit bool
+	// exponentNeedsDigit stops an e that isn't followed by digits from being taken as an exponent, so
+	// that it can start a unit like em
+	exponentNeedsDigit bool
+}
+
+// NoPlusSign makes a leading + an error, as in JSON and many programming languages. A - is still
+// allowed.
+func NoPlusSign() NumberOption {
+	return func(o *numberOptions) {
+		o.noPlus = true
+	}
+}
+
+// NoTrailingDot makes a . that isn't followed by a digit end the number rather than be its decimal
+// point, so 1.foo is the integer 1 followed by .foo, for grammars where . is also an operator. Numbers
+// like 5. are then matched as the integer 5.
+func NoTrailingDot() NumberOption {
+	return func(o *numberOptions) {
+		o.pointNeedsDigit = true
+	}
+}
+
+// MaxDigits makes it an error for the integer part and fraction of a number together to have more
+// than n digits. This bounds the time spent converting untrusted input, which grows with the number of
+// digits. An n of 0 or less means no limit.
+func MaxDigits(n int) NumberOption {
+	return func(o *numberOptions) {
+		o.maxDigits = n
+	}
+}
+
+// LimitedNumberLit matches the same numbers as NumberLit, but with at most maxDigits digits. It is
+// short for NumberLit(MaxDigits(maxDigits)).
+func LimitedNumberLit(maxDigits int) Parser {
+	return NumberLit(MaxDigits(maxDigits))
+}
+
+func numberLit(opts numberOptions) Parser {
+	return NewParser("number literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		if opts.noPlus && strings.HasPrefix(ps.Get(), "+") {
+			ps.ErrorHere("number without + sign")
+			return
+		}
+		end, text, base, float := scanNumberWith(ps, opts)
+		if ps.Errored() {
+			return
+		}
+		if opts.maxDigits > 0 {
+			if pos := excessDigit(ps.Input[:end], ps.Pos, base, opts.maxDigits); pos >= 0 {
+				ps.errorAt(pos, "at most "+strconv.Itoa(opts.maxDigits)+" digits")
+				return
+			}
+		}
+
+		var err error
+		if float {
+			node.Result, err = strconv.ParseFloat(text, 10)
+		} else {
+			node.Result, err = strconv.ParseInt(text, base, 64)
+		}
+		if err != nil {
+			ps.ErrorHere("number")
+			return
+		}
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// StrictNumberLit matches a number following the JSON grammar, and returns it as a int64 or float64 in
+// .Result like NumberLit. Unlike NumberLit it is an error for the integer part to have a leading zero,
+// eg 007, or a + sign, and there are no hex, octal or binary numbers, underscores, or numbers like .5
+// or 5. that leave out part of the mantissa. The exponent may still have either sign.
+func StrictNumberLit() Parser {
+	return NewParser("number literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, float := scanStrictNumber(ps)
+		if ps.Errored() {
+			return
+		}
+
+		var err error
+		if float {
+			node.Result, err = strconv.ParseFloat(ps.Input[ps.Pos:end], 64)
+		} else {
+			node.Result, err = strconv.ParseInt(ps.Input[ps.Pos:end], 10, 64)
+		}
+		if err != nil {
+			ps.ErrorHere("number")
+			return
+		}
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// JSONNumberLit matches the same numbers as StrictNumberLit, but returns the text of the number as a
+// json.Number in .Result rather than converting it, so that integers too big for a float64 keep their
+// precision.
+func JSONNumberLit() Parser {
+	return NewParser("number literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, _ := scanStrictNumber(ps)
+		if ps.Errored() {
+			return
+		}
+
+		node.Token = ps.Input[ps.Pos:end]
+		node.Result = json.Number(node.Token)
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// scanStrictNumber finds the end of a JSON number at the current position, setting an error at the
+// first character that doesn't fit the grammar
+func scanStrictNumber(ps *State) (end int, float bool) {
+	end = ps.Pos
+	inputLen := len(ps.Input)
+	// digits consumes decimal digits, failing if there aren't any
+	digits := func() bool {
+		start := end
+		for end < inputLen && isDecimalDigit(ps.Input[end]) {
+			end++
+		}
+		if end == start {
+			ps.errorAt(end, "number")
+			return false
+		}
+		return true
+	}
+
+	if end < inputLen && ps.Input[end] == '-' {
+		end++
+	}
+	if end < inputLen && ps.Input[end] == '0' {
+		end++
+		if end < inputLen && isDecimalDigit(ps.Input[end]) {
+			ps.errorAt(end, "number without leading zeros")
+			return end, false
+		}
+	} else if !digits() {
+		return end, false
+	}
+
+	if end < inputLen && ps.Input[end] == '.' {
+		float = true
+		end++
+		if !digits() {
+			return end, false
+		}
+	}
+
+	if end < inputLen && (ps.Input[end] == 'e' || ps.Input[end] == 'E') {
+		float = true
+		end, _ = scanSign(ps.Input, end+1)
+		if !digits() {
+			return end, false
+		}
+	}
+	return end, float
+}
+
+// NumberLitRange matches the same numbers as NumberLit, returning them in the same way, but it is an
+// error, at the start of the number, for them to be less than min or greater than max.
+func NumberLitRange(min, max float64) Parser {
+	number := NumberLit()
+	expected := "number from " + strconv.FormatFloat(min, 'g', -1, 64) + " to " + strconv.FormatFloat(max, 'g', -1, 64)
+	return NewParser("number literal", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		number(ps, node)
+		if ps.Errored() {
+			return
+		}
+
+		var inRange bool
+		switch n := node.Result.(type) {
+		case int64:
+			// compare integers exactly where the bounds allow it, as float64 can't hold every int64
+			inRange = float64(n) >= min && float64(n) <= max
+			if min == math.Trunc(min) && max == math.Trunc(max) && min >= math.MinInt64 && max < math.MaxInt64 {
+				inRange = n >= int64(min) && n <= int64(max)
+			}
+		case float64:
+			inRange = n >= min && n <= max
+		}
+		if !inRange {
+			ps.errorAt(node.Start, expected)
+			ps.Pos = startpos
+		}
+	})
+}
+
+// IntLit matches the same integers as NumberLit and returns them as an int64 in .Result, but it is an
+// error for the number to have a fractional part or exponent.
+func IntLit() Parser {
+	return NewParser("integer literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, text, base, float := scanNumber(ps)
+		if ps.Errored() {
+			ps.errorAt(ps.Error.pos, "integer")
+			return
+		}
+		if float {
+			// e is a digit in hex, where the exponent is introduced by p instead
+			notInteger := ".eE"
+			if base == 16 {
+				notInteger = ".pP"
+			}
+			ps.errorAt(ps.Pos+strings.IndexAny(ps.Input[ps.Pos:end], notInteger), "integer")
+			return
+		}
+
+		var err error
+		node.Result, err = strconv.ParseInt(text, base, 64)
+		if err != nil {
+			ps.ErrorHere("integer")
+			return
+		}
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// FloatLit matches the same numbers as NumberLit, but always returns them as a float64 in .Result, even
+// when they have no fractional part or exponent.
+func FloatLit() Parser {
+	return NewParser("float literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, text, base, float := scanNumber(ps)
+		if ps.Errored() {
+			return
+		}
+
+		if base != 10 && !float {
+			i, err := strconv.ParseInt(text, base, 64)
+			if err != nil {
+				ps.ErrorHere("number")
+				return
+			}
+			node.Result = float64(i)
+		} else {
+			f, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				ps.ErrorHere("number")
+				return
+			}
+			node.Result = f
+		}
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// RadixNumberLit matches an integer written with an explicit base between 2 and 36 as in Smalltalk,
+// eg 16rFF, 2r1010 or 36#Z, and returns it as an int64 in .Result. Digits above 9 are letters in either
+// case. The base and the r or # marker may be left out, in which case the integer is decimal.
+func RadixNumberLit() Parser {
+	return NewParser("radix number literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, _ := scanSign(ps.Input, ps.Pos)
+		inputLen := len(ps.Input)
+
+		digits := end
+		for end < inputLen && isDecimalDigit(ps.Input[end]) {
+			end++
+		}
+		if end == digits {
+			ps.errorAt(end, "number")
+			return
+		}
+
+		base := 10
+		text := ps.Input[ps.Pos:end]
+		if end < inputLen && (ps.Input[end] == 'r' || ps.Input[end] == '#') {
+			var err error
+			base, err = strconv.Atoi(ps.Input[digits:end])
+			if err != nil || base < 2 || base > 36 {
+				ps.errorAt(digits, "base from 2 to 36")
+				return
+			}
+
+			end++
+			text = ps.Input[ps.Pos:digits]
+			digits = end
+			for end < inputLen && isRadixDigit(ps.Input[end]) {
+				if radixDigitVal(ps.Input[end]) >= base {
+					ps.errorAt(end, "base "+strconv.Itoa(base)+" digit")
+					return
+				}
+				end++
+			}
+			if end == digits {
+				ps.errorAt(end, "base "+strconv.Itoa(base)+" digit")
+				return
+			}
+			text += ps.Input[digits:end]
+		}
+
+		i, err := strconv.ParseInt(text, base, 64)
+		if err != nil {
+			ps.ErrorHere("number")
+			return
+		}
+		node.Result = i
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+func isRadixDigit(c byte) bool {
+	return radixDigitVal(c) < 36
+}
+
+// radixDigitVal returns the value of a digit in bases up to 36, or 36 if c is not a digit
+func radixDigitVal(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'z':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return 36
+}
+
+// ScientificLit matches a decimal number written in scientific notation, eg 6.022e23, and returns it
+// as a float64 in .Result. Unlike FloatLit the exponent is required, so it is an error for the number
+// to end without one.
+func ScientificLit() Parser {
+	return NewParser("scientific literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, text, base, _ := scanNumber(ps)
+		if ps.Errored() {
+			return
+		}
+
+		if base != 10 || !strings.ContainsAny(text, "eE") {
+			ps.errorAt(end, "exponent")
+			return
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			ps.ErrorHere("number")
+			return
+		}
+		node.Result = f
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// SpecialFloatLit matches the same numbers as FloatLit, plus the given names for non-finite floats,
+// which may be signed and are matched case insensitively. The names may be any of NaN, Inf and
+// Infinity; if none are given all three are allowed. eg SpecialFloatLit("Inf") matches -inf but not NaN.
+func SpecialFloatLit(names ...string) Parser {
+	if len(names) == 0 {
+		names = []string{"NaN", "Inf", "Infinity"}
+	}
+	sorted := append([]string(nil), names...)
+	for _, name := range sorted {
+		if !strings.EqualFold(name, "nan") && !strings.EqualFold(name, "inf") && !strings.EqualFold(name, "infinity") {
+			panic(fmt.Errorf("%s is not a special float", name))
+		}
+	}
+	// try longer names first so that infinity isn't matched as inf
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	float := FloatLit()
+
+	return NewParser("float literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		start, negative := scanSign(ps.Input, ps.Pos)
+		sign := 1
+		if negative {
+			sign = -1
+		}
+
+		for _, name := range sorted {
+			end := start + len(name)
+			if end > len(ps.Input) || !strings.EqualFold(ps.Input[start:end], name) || (end < len(ps.Input) && isIdentByte(ps.Input[end])) {
+				continue
+			}
+			if strings.EqualFold(name, "nan") {
+				node.Result = math.NaN()
+			} else {
+				node.Result = math.Inf(sign)
+			}
+			node.Token = ps.Input[ps.Pos:end]
+			node.Start = ps.Pos
+			node.End = end
+			ps.Pos = end
+			return
+		}
+
+		float(ps, node)
+	})
+}
+
+// SuffixedNumberLit matches the same numbers as NumberLit, optionally followed by one of the given type
+// suffixes, eg 100L, 3.14f or 0xFFu8. The number is returned as for NumberLit, and a suffix that was
+// found is returned as .Child[0]. A suffix that runs straight into more letters or digits is not
+// consumed, so the start of a following identifier is left alone.
+func SuffixedNumberLit(suffixes ...string) Parser {
+	number := NumberLit()
+	sorted := append([]string(nil), suffixes...)
+	// try longer suffixes first so that u8 isn't matched as u
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	return NewParser("number literal", func(ps *State, node *Result) {
+		number(ps, node)
+		if ps.Errored() {
+			return
+		}
+		node.Child = nil
+
+		for _, suffix := range sorted {
+			end := ps.Pos + len(suffix)
+			if !strings.HasPrefix(ps.Get(), suffix) || (end < len(ps.Input) && isIdentByte(ps.Input[end])) {
+				continue
+			}
+			node.Child = []Result{{Token: suffix, Input: node.Input, Start: ps.Pos, End: end}}
+			node.End = end
+			ps.Pos = end
+			return
+		}
+	})
+}
+
+// Measure is a number with a unit, as returned by MeasureLit
+type Measure struct {
+	Value float64
+	Unit  string
+}
+
+// String formats the measure as it would be written, eg 1.5em
+func (m Measure) String() string {
+	return strconv.FormatFloat(m.Value, 'g', -1, 64) + m.Unit
+}
+
+// MeasureLit matches a number as for NumberLit followed directly by one of units, eg 10px or 1.5em,
+// and returns both as a Measure in .Result. The number is also returned as .Child[0], as NumberLit
+// would return it, and the unit in .Token. A unit that runs straight into more letters or digits
+// doesn't count, and it is an error for there not to be one.
+func MeasureLit(units []string) Parser {
+	number := numberLit(numberOptions{exponentNeedsDigit: true})
+	sorted := append([]string(nil), units...)
+	// try longer units first so that em isn't matched in ems
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	expected := strings.Join(units, " or ")
+
+	return NewParser("measure literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		startpos := ps.Pos
+		value := Result{Input: node.Input}
+		number(ps, &value)
+		if ps.Errored() {
+			return
+		}
+
+		for _, unit := range sorted {
+			end := ps.Pos + len(unit)
+			if !strings.HasPrefix(ps.Get(), unit) || (end < len(ps.Input) && isIdentByte(ps.Input[end])) {
+				continue
+			}
+			f, _ := value.AsFloat64()
+			node.Result = Measure{Value: f, Unit: unit}
+			node.Token = unit
+			node.Child = ps.Children(1)
+			node.Child[0] = value
+			node.Start = startpos
+			node.End = end
+			ps.Pos = end
+			return
+		}
+		ps.ErrorHere(expected)
+		ps.Pos = startpos
+	})
+}
+
+// PercentLit matches a number as for NumberLit followed directly by a %, eg 50% or 12.5%, and returns
+// the number divided by 100 as a float64 in .Result, and the text including the % in .Token. There may
+// not be any whitespace before the %.
+func PercentLit() Parser {
+	number := NumberLit()
+
+	return NewParser("percent literal", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		number(ps, node)
+		if ps.Errored() {
+			return
+		}
+		if !strings.HasPrefix(ps.Get(), "%") {
+			ps.ErrorHere("%")
+			ps.Pos = startpos
+			return
+		}
+
+		switch n := node.Result.(type) {
+		case int64:
+			node.Result = float64(n) / 100
+		case float64:
+			node.Result = n / 100
+		}
+		ps.Pos++
+		node.End = ps.Pos
+		node.Token = ps.Input[node.Start:node.End]
+	})
+}
+
+// siPrefixes are the SI prefixes allowed by SINumberLit, with the power of ten each stands for
+var siPrefixes = []struct {
+	name     string
+	exponent int
+}{
+	{"Y", 24}, {"Z", 21}, {"E", 18}, {"P", 15}, {"T", 12}, {"G", 9}, {"M", 6}, {"k", 3},
+	{"m", -3},
+	{"µ", -6}, // U+00B5 micro sign
+	{"μ", -6}, // U+03BC greek mu
+	{"u", -6},
+	{"n", -9}, {"p", -12}, {"f", -15}, {"a", -18}, {"z", -21}, {"y", -24},
+}
+
+// SINumberLit matches a number as for NumberLit followed directly by an optional SI prefix, eg 1k,
+// 2.5M or 10µ, and returns the number scaled by the prefix as a float64 in .Result. The prefixes are
+// those for powers of a thousand from y (10^-24) to Y (10^24), with u accepted for µ. A prefix that runs
+// straight into more letters or digits isn't one, and is left unconsumed along with them.
+func SINumberLit() Parser {
+	number := numberLit(numberOptions{exponentNeedsDigit: true})
+
+	return NewParser("number literal", func(ps *State, node *Result) {
+		number(ps, node)
+		if ps.Errored() {
+			return
+		}
+		value, _ := node.AsFloat64()
+
+		for _, prefix := range siPrefixes {
+			end := ps.Pos + len(prefix.name)
+			if !strings.HasPrefix(ps.Get(), prefix.name) || (end < len(ps.Input) && isIdentByte(ps.Input[end])) {
+				continue
+			}
+			// dividing by an exact power of ten rounds better than multiplying by an inexact one
+			if prefix.exponent > 0 {
+				value *= math.Pow10(prefix.exponent)
+			} else {
+				value /= math.Pow10(-prefix.exponent)
+			}
+			node.End = end
+			ps.Pos = end
+			break
+		}
+		node.Result = value
+		node.Token = ps.Input[node.Start:node.End]
+	})
+}
+
+// ComplexLit matches a complex number written as a real part, an imaginary part ending in i, or both
+// joined by a + or -, eg 3, 2i, i, 3+4i or 1.5-i, and returns it as a complex128 in .Result. Each part
+// is a number as for NumberLit, and there may not be any whitespace inside the literal. When the part
+// after a + or - doesn't end in i only the real part is matched, so that 3+4 can still be parsed as an
+// addition.
+func ComplexLit() Parser {
+	number := NoAutoWS(NumberLit())
+
+	return NewParser("complex literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		start := ps.Pos
+
+		// imaginary matches the i ending an imaginary part at pos, returning the position after it
+		imaginary := func(pos int) (int, bool) {
+			if pos < len(ps.Input) && ps.Input[pos] == 'i' && (pos+1 == len(ps.Input) || !isIdentByte(ps.Input[pos+1])) {
+				return pos + 1, true
+			}
+			return pos, false
+		}
+		// part matches a number, or a lone sign before an i, leaving the i for imaginary
+		part := func() (float64, bool) {
+			end, negative := scanSign(ps.Input, ps.Pos)
+			if _, ok := imaginary(end); ok {
+				ps.Pos = end
+				if negative {
+					return -1, true
+				}
+				return 1, true
+			}
+			component := Result{}
+			number(ps, &component)
+			if ps.Errored() {
+				return 0, false
+			}
+			value, _ := component.AsFloat64()
+			return value, true
+		}
+
+		re, ok := part()
+		if !ok {
+			if ps.Error.pos == start {
+				ps.ErrorHere("complex number")
+			}
+			ps.Pos = start
+			return
+		}
+
+		var value complex128
+		if end, ok := imaginary(ps.Pos); ok {
+			value = complex(0, re)
+			ps.Pos = end
+		} else {
+			value = complex(re, 0)
+			realEnd := ps.Snapshot()
+			if end, _ := scanSign(ps.Input, ps.Pos); end > ps.Pos {
+				im, ok := part()
+				if end, isImaginary := imaginary(ps.Pos); ok && isImaginary {
+					value = complex(re, im)
+					ps.Pos = end
+				} else {
+					// not an imaginary part, so leave it for whatever comes next
+					ps.Restore(realEnd)
+				}
+			}
+		}
+		node.Result = value
+		node.Token = ps.Input[start:ps.Pos]
+		node.Start = start
+		node.End = ps.Pos
+	})
+}
+
+// durationUnits are the units allowed by DurationLit, longest first so that ms isn't matched as m
+var durationUnits = []struct {
+	name string
+	unit time.Duration
+}{
+	{"µs", time.Microsecond}, // U+00B5 micro sign
+	{"μs", time.Microsecond}, // U+03BC greek mu
+	{"ns", time.Nanosecond},
+	{"us", time.Microsecond},
+	{"ms", time.Millisecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+}
+
+// DurationLit matches a duration written as a sequence of numbers each followed by a unit, eg 1h30m,
+// 1.5s or -250ms, and returns it as a time.Duration in .Result. The units are the same as for
+// time.ParseDuration: ns, us (or µs), ms, s, m and h, and so are the numbers, which are plain decimal
+// digits with an optional fraction and no exponent or underscores. A bare 0 needs no unit. It is an
+// error for the duration not to fit in a time.Duration.
+func DurationLit() Parser {
+	return NewParser("duration literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		start := ps.Pos
+		end, negative := scanSign(ps.Input, start)
+
+		// fail sets the error and puts ps.Pos back where it was
+		fail := func(expected string, pos int) {
+			ps.errorAt(pos, expected)
+			ps.Pos = start
+		}
+		tooLong := "duration up to " + time.Duration(math.MaxInt64).String()
+
+		var total time.Duration
+		for first := true; ; first = false {
+			// plain digits with an optional fraction, as time.ParseDuration takes them
+			component := end
+			for end < len(ps.Input) && isDecimalDigit(ps.Input[end]) {
+				end++
+			}
+			float := end < len(ps.Input) && ps.Input[end] == '.'
+			if float {
+				end++
+				for end < len(ps.Input) && isDecimalDigit(ps.Input[end]) {
+					end++
+				}
+			}
+			text := ps.Input[component:end]
+			if text == "" || text == "." {
+				if first {
+					fail("duration", component)
+					return
+				}
+				end = component
+				break
+			}
+
+			number := end
+			unit := time.Duration(0)
+			for _, u := range durationUnits {
+				if strings.HasPrefix(ps.Input[end:], u.name) {
+					unit = u.unit
+					end += len(u.name)
+					break
+				}
+			}
+			// a bare 0 needs no unit, as in time.ParseDuration
+			if unit == 0 && first && text == "0" && (end == len(ps.Input) || !isIdentByte(ps.Input[end])) {
+				break
+			}
+			// a unit that runs into more letters, eg mo, isn't a unit at all
+			if unit == 0 || (end < len(ps.Input) && isIdentByte(ps.Input[end]) && !isDecimalDigit(ps.Input[end])) {
+				fail("duration unit", number)
+				return
+			}
+
+			var value time.Duration
+			if float {
+				f, err := strconv.ParseFloat(text, 64)
+				if err != nil || f*float64(unit) >= math.MaxInt64 {
+					fail(tooLong, component)
+					return
+				}
+				value = time.Duration(f * float64(unit))
+			} else {
+				i, err := strconv.ParseInt(text, 10, 64)
+				if err != nil || i > int64(math.MaxInt64/unit) {
+					fail(tooLong, component)
+					return
+				}
+				value = time.Duration(i) * unit
+			}
+			if total > math.MaxInt64-value {
+				fail(tooLong, component)
+				return
+			}
+			total += value
+		}
+
+		if negative {
+			total = -total
+		}
+		node.Result = total
+		node.Token = ps.Input[start:end]
+		node.Start = start
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// TimeLit matches a date or time written as in layout, which is given to time.Parse, and returns it as
+// a time.Time in .Result, eg TimeLit(time.RFC3339) matches 2006-01-02T15:04:05Z. The time is taken to
+// be as many words separated by whitespace as there are in the layout, less any punctuation after the
+// last that doesn't fit it, so a time may be followed by a comma or bracket but must otherwise be
+// followed by whitespace or the end of the input.
+func TimeLit(layout string) Parser {
+	words := len(strings.Fields(layout))
+	return NewParser("time literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		runes := ps.Runes()
+		for i := 0; i < words; i++ {
+			if i > 0 {
+				skipSpaces(&runes)
+			}
+			for !runes.Done() {
+				if r, _ := runes.Peek(); unicode.IsSpace(r) {
+					break
+				}
+				runes.Next()
+			}
+		}
+		end := runes.Pos()
+
+		for end > ps.Pos {
+			t, err := time.Parse(layout, ps.Input[ps.Pos:end])
+			if err == nil {
+				node.Result = t
+				node.Token = ps.Input[ps.Pos:end]
+				node.Start = ps.Pos
+				node.End = end
+				ps.Pos = end
+				return
+			}
+			r, size := utf8.DecodeLastRuneInString(ps.Input[ps.Pos:end])
+			if !unicode.IsPunct(r) {
+				break
+			}
+			end -= size
+		}
+		ps.ErrorHere("time like " + layout)
+	})
+}
+
+// skipSpaces moves runes past any whitespace
+func skipSpaces(runes *RuneScanner) {
+	for !runes.Done() {
+		if r, _ := runes.Peek(); !unicode.IsSpace(r) {
+			return
+		}
+		runes.Next()
+	}
+}
+
+// LocalizedNumberLit matches a number written with the given digit grouping and decimal separators, eg
+// 1,234.56 or 1.234,56, and returns it as a int64 or float64 in .Result. Groups after the first must
+// be exactly three digits long, and the first between one and three. A separator that isn't followed by
+// a digit ends the number, so lists like 1, 2, 3 still parse. It panics if the two separators are the
+// same.
+func LocalizedNumberLit(groupSep, decimalSep rune) Parser {
+	if groupSep == decimalSep {
+		panic(fmt.Errorf("%c can't separate both digit groups and decimals", groupSep))
+	}
+	group, decimal := string(groupSep), string(decimalSep)
+	return NewParser("number literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end := ps.Pos
+		inputLen := len(ps.Input)
+		text := &bytes.Buffer{}
 
-			if buf == nil {
-				buf = bytes.NewBufferString(ps.Input[node.Start:end])
-			}
+		end, _ = scanSign(ps.Input, end)
+		text.WriteString(ps.Input[ps.Pos:end])
 
-			c, s := utf8.DecodeRuneInString(ps.Input[end+size:])
-			if c == 'u' {
-				if end+size+s+4 >= inputLen {
-					ps.Error.expected = "[a-f0-9]{4}"
-					ps.Error.pos = end + size + s
-					return false
-				}
+		followedByDigit := func(sep string) bool {
+			return strings.HasPrefix(ps.Input[end:], sep) && end+len(sep) < inputLen && isDecimalDigit(ps.Input[end+len(sep)])
+		}
 
-				r, ok := unhex(ps.Input[end+size+s : end+size+s+4])
-				if !ok {
-					ps.Error.expected = "[a-f0-9]"
-					ps.Error.pos = end + size + s
-					return false
-				}
-				buf.WriteRune(r)
-				end += size + s + 4
-			} else {
-				if c == closer {
-					buf.WriteRune(c)
-				} else {
-					replacement, ok := escapes[c]
-					if ok {
-						buf.WriteRune(replacement)
-					} else {
-						// write both the slash and the following character
-						buf.WriteRune(current)
-						buf.WriteRune(c)
-					}
-				}
-				end += size + s
+		mantissa := end
+		groupLen, lastSep := 0, -1
+		for end < inputLen {
+			if isDecimalDigit(ps.Input[end]) {
+				text.WriteByte(ps.Input[end])
+				groupLen++
+				end++
+				continue
 			}
-		case closer:
-			if buf == nil {
-				node.Token = ps.Input[node.Start:end]
-				ps.Pos = end + size
-				return true
+			if !followedByDigit(group) {
+				break
 			}
-			ps.Pos = end + size
-			node.Token = buf.String()
-			return true
-		default:
-			end += size
-			if buf != nil {
-				buf.WriteRune(current)
+			if groupLen == 0 || groupLen > 3 || (lastSep >= 0 && groupLen != 3) {
+				ps.errorAt(end, "number")
+				return
 			}
+			lastSep = end
+			groupLen = 0
+			end += len(group)
 		}
-	}
-	ps.ErrorHere(string(closer))
-	return false
-}
 
-// StringLit matches a quoted string and returns it in .Token. It may contain:
-//  - unicode
-//  - escaped characters, eg \", \n, \t
-//  - unicode sequences, eg \uBEEF
-// allowedQuotes is the list of allowed quote characters; both the
-// opening and closing quotes will be the same character from this
-// string
-func StringLit(allowedQuotes string) Parser {
-	return NewParser("string literal", func(ps *State, node *Result) {
-		ps.WS(ps)
+		if end == mantissa {
+			ps.errorAt(end, "number")
+			return
+		}
+		if lastSep >= 0 && groupLen != 3 {
+			ps.errorAt(lastSep, "number")
+			return
+		}
 
-		opener, size := utf8.DecodeRuneInString(ps.Input[ps.Pos:])
-		if !stringContainsRune(allowedQuotes, opener) {
-			ps.ErrorHere(allowedQuotes)
+		float := followedByDigit(decimal)
+		if float {
+			text.WriteByte('.')
+			end += len(decimal)
+			for end < inputLen && isDecimalDigit(ps.Input[end]) {
+				text.WriteByte(ps.Input[end])
+				end++
+			}
+		}
+
+		var err error
+		if float {
+			node.Result, err = strconv.ParseFloat(text.String(), 64)
+		} else {
+			node.Result, err = strconv.ParseInt(text.String(), 10, 64)
+		}
+		if err != nil {
+			ps.ErrorHere("number")
 			return
 		}
-		node.Start = ps.Pos + size
-		stringImpl(ps, node, opener, _Escapes)
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
 	})
 }
 
-// UnicodeStringLiteral matches a quoted string and returns it in .Token. It may contain:
-//  - unicode
-//  - escaped characters, eg \", \n, \t
-//  - unicode sequences, eg \uBEEF
-// The opening and closing quote character may be any matched pair of
-// unicode characters from the Pi/Pf categories, or from the Ps/Pe
-// categories, plus angle brackets, or if they may be a punctuation
-// character, as long as they are the same punctuation character.
-func UnicodeStringLiteral() Parser {
-	return CustomStringLiteral(IsValidRegexpDelimiter, _Escapes)
-}
+// RangeLit matches a range of two integers separated by one of the given separators, eg 1..10, and
+// returns the integers as parsed by IntLit in .Child[0] and .Child[1], and the separator in .Token.
+// If no separators are given .. and ... are allowed.
+func RangeLit(separators ...string) Parser {
+	if len(separators) == 0 {
+		separators = []string{"..", "..."}
+	}
+	sorted := append([]string(nil), separators...)
+	// try longer separators first so that ... isn't matched as ..
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	number := IntLit()
 
-// CustomStringLiteral matches a quoted string and returns it in .Token. It may contain:
-//  - unicode
-//  - escaped characters, eg \", \n, \t
-//  - unicode sequences, eg \uBEEF
-// The opening and closing quotes are validated by the isValid
-// function you pass in. This function should return true if its
-// argument is a valid opening quote character, plus the correct
-// closing quote character that ends the string. See
-// IsValidRegexpDelimiter.
-//
-// The only valid escape characters are those defined in the escapes
-// argument, plus one for the closer returned by isValid.
-func CustomStringLiteral(isValid func(rune) (bool, rune), escapes map[rune]rune) Parser {
-	return NewParser("string literal", func(ps *State, node *Result) {
-		ps.WS(ps)
+	return NewParser("range literal", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		node.Child = ps.Children(2)
+		node.Child[0].Input = node.Input
+		node.Child[1].Input = node.Input
 
-		opener, size := utf8.DecodeRuneInString(ps.Input[ps.Pos:])
-		valid, closer := isValid(opener)
-		if !valid {
-			ps.ErrorHere("string delimiter")
+		number(ps, &node.Child[0])
+		if ps.Errored() {
 			return
 		}
-		node.Start = ps.Pos + size
-		matched := stringImpl(ps, node, closer, escapes)
-		if !matched {
-			ps.ErrorHere(string("string delimiter"))
+
+		ps.WS(ps)
+		separator := ""
+		for _, s := range sorted {
+			if strings.HasPrefix(ps.Get(), s) {
+				separator = s
+				break
+			}
 		}
-	})
-}
+		if separator == "" {
+			ps.ErrorHere(strings.Join(separators, " or "))
+			ps.Pos = startpos
+			return
+		}
+		ps.Advance(len(separator))
 
-func UnicodeRegexpMatchLiteral() Parser {
-	return CustomRegexpMatchLiteral(IsValidRegexpDelimiter, _Escapes)
+		number(ps, &node.Child[1])
+		if ps.Errored() {
+			ps.Pos = startpos
+			return
+		}
+		node.Token = separator
+		node.Start = node.Child[0].Start
+		node.End = ps.Pos
+	})
 }
 
-func CustomRegexpMatchLiteral(isValid func(rune) (bool, rune), escapes map[rune]rune) Parser {
-	return NewParser("regexp match literal", func(ps *State, node *Result) {
+// BigNumberLit matches the same literals as NumberLit, but returns them as a *big.Int or *big.Float
+// in .Result, with the matched text in .Token, so that they never overflow. Floats get at least 64
+// bits of precision, and more for long literals.
+func BigNumberLit() Parser {
+	return NewParser("big number literal", func(ps *State, node *Result) {
 		ps.WS(ps)
-
-		opener, size := utf8.DecodeRuneInString(ps.Input[ps.Pos:])
-		valid, closer := isValid(opener)
-		if !valid {
-			ps.ErrorHere("regexp delimiter")
+		end, text, base, float := scanNumber(ps)
+		if ps.Errored() {
 			return
 		}
-		node.Start = ps.Pos + size
-		matched := stringImpl(ps, node, closer, escapes)
-		if !matched {
-			ps.ErrorHere(string(closer))
+
+		if float {
+			prec := uint(len(text)) * 4
+			if prec < 64 {
+				prec = 64
+			}
+			// base 0 lets hex floats be recognised by their 0x prefix
+			f, _, err := big.ParseFloat(text, 0, prec, big.ToNearestEven)
+			if err != nil {
+				ps.ErrorHere("number")
+				return
+			}
+			node.Result = f
+		} else {
+			i, ok := new(big.Int).SetString(text, base)
+			if !ok {
+				ps.ErrorHere("number")
+				return
+			}
+			node.Result = i
 		}
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
 	})
 }
 
-func UnicodeRegexpReplaceLiteral() Parser {
-	return CustomRegexpReplaceLiteral(IsValidRegexpDelimiter, _Escapes)
+// Decimal is an exact fixed point number, returned by DecimalLit. Its value is Coefficient / 10^Scale,
+// so 3.14 is {314, 2}.
+type Decimal struct {
+	Coefficient int64
+	Scale       int
 }
 
-func CustomRegexpReplaceLiteral(isValid func(rune) (bool, rune), escapes map[rune]rune) Parser {
-	return NewParser("regexp replace literal", func(ps *State, node *Result) {
-		ps.WS(ps)
+// String formats the decimal with Scale digits after the decimal point.
+func (d Decimal) String() string {
+	digits := strconv.FormatInt(d.Coefficient, 10)
+	sign := ""
+	if d.Coefficient < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if d.Scale <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+}
 
-		child1 := *node
-		opener, size := utf8.DecodeRuneInString(ps.Input[ps.Pos:])
-		valid, closer := isValid(opener)
-		if !valid {
-			ps.ErrorHere("regexp delimiter")
+// DecimalLit matches a decimal number as for NumberLit and returns it exactly as a Decimal in .Result,
+// without rounding it to a float64, eg 3.14 is {314, 2}. An exponent moves the decimal point, so 1.5e3
+// is {1500, 0} and 15e-4 is {15, 4}. It is an error for the number to have too many digits to fit in
+// the int64 coefficient.
+func DecimalLit() Parser {
+	return NewParser("decimal literal", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end, text, base, float := scanNumber(ps)
+		if ps.Errored() {
+			return
+		}
+		if base != 10 {
+			ps.ErrorHere("decimal number")
 			return
 		}
-		ps.Pos += size
-		child1.Start = ps.Pos
 
-		matched := stringImpl(ps, &child1, closer, escapes)
-		if !matched {
-			ps.ErrorHere(string(closer))
+		d, ok := Decimal{}, true
+		if float {
+			d, ok = parseDecimal(text)
+		} else {
+			d.Coefficient, ok = parseCoefficient(text)
+		}
+		if !ok {
+			ps.ErrorHere("number")
 			return
 		}
+		node.Result = d
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
 
-		child2 := *node
-		if closer != opener {
-			opener, size = utf8.DecodeRuneInString(ps.Input[ps.Pos:])
-			valid, closer = IsValidRegexpDelimiter(opener)
-			ps.Pos += size
-			if !valid {
-				ps.ErrorHere("regexp delimiter")
-				return
-			}
+// parseDecimal converts the text of a decimal float found by scanNumber into a Decimal, reporting
+// false if it doesn't fit
+func parseDecimal(text string) (Decimal, bool) {
+	mantissa, exponent := text, 0
+	if e := strings.IndexAny(text, "eE"); e >= 0 {
+		var err error
+		mantissa = text[:e]
+		if exponent, err = strconv.Atoi(text[e+1:]); err != nil {
+			return Decimal{}, false
 		}
-		child2.Start = ps.Pos
+	}
 
-		matched = stringImpl(ps, &child2, closer, _Escapes)
-		if !matched {
-			ps.ErrorHere(string(closer))
-			return
+	var d Decimal
+	if point := strings.IndexByte(mantissa, '.'); point >= 0 {
+		d.Scale = len(mantissa) - point - 1
+		mantissa = mantissa[:point] + mantissa[point+1:]
+	}
+	// strconv wants at least one digit, but 5. and .5 only have them on one side of the point
+	if mantissa == "" || mantissa == "-" || mantissa == "+" {
+		mantissa += "0"
+	}
+	coefficient, ok := parseCoefficient(mantissa)
+	if !ok {
+		return Decimal{}, false
+	}
+	d.Coefficient = coefficient
+
+	if exponent < -math.MaxInt32 || exponent > math.MaxInt32 {
+		return Decimal{}, false
+	}
+	d.Scale -= exponent
+	if d.Coefficient == 0 && d.Scale < 0 {
+		// zero never overflows, so scaling it up digit by digit could take billions of steps
+		d.Scale = 0
+	}
+	for ; d.Scale < 0; d.Scale++ {
+		if d.Coefficient > math.MaxInt64/10 || d.Coefficient < math.MinInt64/10 {
+			return Decimal{}, false
 		}
+		d.Coefficient *= 10
+	}
+	return d, true
+}
 
-		node.Child = []Result{child1, child2}
-	})
+func parseCoefficient(text string) (int64, bool) {
+	i, err := strconv.ParseInt(text, 10, 64)
+	return i, err == nil
 }
 
-// NumberLit matches a floating point or integer number and returns it as a int64 or float64 in .Result
-func NumberLit() Parser {
-	return NewParser("number literal", func(ps *State, node *Result) {
+// VersionLit matches a version number made of integers separated by dots, eg 1.2.3, and returns the
+// integers as an []int in .Result and the text in .Token. It may be followed by a prerelease tag after
+// a -, as in semantic versioning, eg 1.0.0-rc.1, which is included in .Token. It is an error for a dot
+// not to be followed by a digit, so 1..2 and 1.2. are not versions. The integers are plain decimal
+// digits, without the underscores NumberLit allows.
+func VersionLit() Parser {
+	return NewParser("version literal", func(ps *State, node *Result) {
 		ps.WS(ps)
+		var segments []int
 		end := ps.Pos
-		float := false
-		inputLen := len(ps.Input)
+		for {
+			start := end
+			for end < len(ps.Input) && isDecimalDigit(ps.Input[end]) {
+				end++
+			}
+			segment, err := strconv.Atoi(ps.Input[start:end])
+			if err != nil {
+				ps.errorAt(start, "number")
+				return
+			}
+			segments = append(segments, segment)
 
-		if end < inputLen && (ps.Input[end] == '-' || ps.Input[end] == '+') {
+			if end >= len(ps.Input) || ps.Input[end] != '.' {
+				break
+			}
 			end++
 		}
 
-		for end < inputLen && ps.Input[end] >= '0' && ps.Input[end] <= '9' {
-			end++
+		if strings.HasPrefix(ps.Input[end:], "-") {
+			end = scanPrerelease(ps.Input, end)
 		}
 
-		if end < inputLen && ps.Input[end] == '.' {
-			float = true
-			end++
+		node.Result = segments
+		node.Token = ps.Input[ps.Pos:end]
+		node.Start = ps.Pos
+		node.End = end
+		ps.Pos = end
+	})
+}
+
+// scanPrerelease returns the end of the prerelease tag starting with the - at pos, which is made of
+// letters, digits and hyphens in groups separated by single dots. If there isn't one it returns pos.
+func scanPrerelease(input string, pos int) int {
+	end := pos
+	next := pos + 1
+	for {
+		start := next
+		for next < len(input) && ((isIdentByte(input[next]) && input[next] != '_') || input[next] == '-') {
+			next++
+		}
+		if next == start {
+			return end
+		}
+		end = next
+		if next >= len(input) || input[next] != '.' {
+			return end
 		}
+		next++
+	}
+}
 
-		for end < inputLen && ps.Input[end] >= '0' && ps.Input[end] <= '9' {
-			end++
+// scanNumber finds the end of the number literal at ps.Pos without consuming it. It returns the
+// literal's text ready to hand to strconv, with digit separators and any integer base prefix removed,
+// along with its base and whether it is a float. Hex floats keep their 0x prefix, as strconv.ParseFloat
+// needs it. On failure ps.Error is set.
+func scanNumber(ps *State) (end int, text string, base int, float bool) {
+	return scanNumberWith(ps, numberOptions{})
+}
+
+// scanNumberWith is scanNumber for a NumberLit with the given options
+func scanNumberWith(ps *State, opts numberOptions) (end int, text string, base int, float bool) {
+	// point reports whether there is a decimal point at pos
+	point := func(pos int, base int) bool {
+		if pos >= len(ps.Input) || ps.Input[pos] != '.' {
+			return false
 		}
+		return !opts.pointNeedsDigit || (pos+1 < len(ps.Input) && isDigitChar(ps.Input[pos+1], base))
+	}
+	// startsExponent reports whether the e at pos starts an exponent
+	startsExponent := func(pos int) bool {
+		digits, _ := scanSign(ps.Input, pos+1)
+		return !opts.exponentNeedsDigit || (digits < len(ps.Input) && isDecimalDigit(ps.Input[digits]))
+	}
 
-		if end < inputLen && (ps.Input[end] == 'e' || ps.Input[end] == 'E') {
-			end++
-			float = true
+	end, _ = scanSign(ps.Input, ps.Pos)
+	inputLen := len(ps.Input)
 
-			if end < inputLen && (ps.Input[end] == '-' || ps.Input[end] == '+') {
-				end++
-			}
+	var errPos int
+	if base = numberBase(ps.Input[end:]); base != 10 {
+		sign := ps.Input[ps.Pos:end]
+		digits := end + 2
+		end, errPos = scanDigits(ps.Input, digits, base)
+		mantissaDigits := end - digits
 
-			for end < inputLen && ps.Input[end] >= '0' && ps.Input[end] <= '9' {
-				end++
+		// hex floats like 0x1.8p3 have an optional fraction, and a binary exponent that is only
+		// optional when there is no fraction
+		if base == 16 && errPos < 0 && point(end, base) {
+			float = true
+			fraction := end + 1
+			end, errPos = scanDigits(ps.Input, fraction, base)
+			mantissaDigits += end - fraction
+		}
+		if errPos < 0 && mantissaDigits == 0 {
+			errPos = digits
+		}
+		if base == 16 && errPos < 0 && end < inputLen && (ps.Input[end] == 'p' || ps.Input[end] == 'P') {
+			float = true
+			end, _ = scanSign(ps.Input, end+1)
+			exponent := end
+			end, errPos = scanDigits(ps.Input, exponent, 10)
+			if errPos < 0 && end == exponent {
+				errPos = end
 			}
+		} else if float && errPos < 0 {
+			errPos = end
 		}
 
-		if end == ps.Pos {
-			ps.ErrorHere("number")
+		if errPos >= 0 {
+			ps.errorAt(errPos, "number")
 			return
 		}
-
-		var err error
 		if float {
-			node.Result, err = strconv.ParseFloat(ps.Input[ps.Pos:end], 10)
-		} else {
-			node.Result, err = strconv.ParseInt(ps.Input[ps.Pos:end], 10, 64)
+			return end, sign + "0x" + stripUnderscores(ps.Input[digits:end]), base, true
 		}
-		if err != nil {
-			ps.ErrorHere("number")
-			return
+		return end, sign + stripUnderscores(ps.Input[digits:end]), base, false
+	}
+
+	// either the integer part or the fraction may be left out, eg 5. or .5, but not both
+	mantissa := end
+	end, errPos = scanDigits(ps.Input, end, 10)
+	digits := end - mantissa
+
+	// a second dot means this is a range like 1..10 rather than a decimal point
+	if errPos < 0 && point(end, 10) && !strings.HasPrefix(ps.Input[end+1:], ".") {
+		float = true
+		fraction := end + 1
+		end, errPos = scanDigits(ps.Input, fraction, 10)
+		digits += end - fraction
+	}
+
+	if errPos < 0 && digits == 0 {
+		// point past any sign, so a lone - or + says what was missing
+		errPos = mantissa
+	}
+
+	if errPos < 0 && end < inputLen && (ps.Input[end] == 'e' || ps.Input[end] == 'E') && startsExponent(end) {
+		end, _ = scanSign(ps.Input, end+1)
+		float = true
+
+		exponent := end
+		end, errPos = scanDigits(ps.Input, exponent, 10)
+		if errPos < 0 && end == exponent {
+			errPos = end
 		}
-		node.Start = ps.Pos
-		node.End = end
-		ps.Pos = end
-	})
+	}
+
+	if errPos >= 0 {
+		ps.errorAt(errPos, "number")
+		return
+	}
+
+	return end, stripUnderscores(ps.Input[ps.Pos:end]), base, float
 }
 
 func stringContainsRune(s string, r rune) bool {
@@ -277,6 +2835,120 @@ func stringContainsRune(s string, r rune) bool {
 	return false
 }
 
+// scanSign skips an optional + or - at pos in input, returning the position after it and whether it
+// was a -
+func scanSign(input string, pos int) (int, bool) {
+	if pos < len(input) && (input[pos] == '-' || input[pos] == '+') {
+		return pos + 1, input[pos] == '-'
+	}
+	return pos, false
+}
+
+// numberBase returns the base selected by a 0x, 0o or 0b prefix at the start of s, or 10 if there is none
+func numberBase(s string) int {
+	if len(s) < 2 || s[0] != '0' {
+		return 10
+	}
+	switch s[1] {
+	case 'x', 'X':
+		return 16
+	case 'o', 'O':
+		return 8
+	case 'b', 'B':
+		return 2
+	}
+	return 10
+}
+
+// scanDigits consumes digits in the given base starting at end, allowing single underscores between
+// digits. It returns the position after the last digit, and the position of the first misplaced
+// underscore or out of range digit, or -1 if there was none.
+func scanDigits(input string, end int, base int) (int, int) {
+	start := end
+	for end < len(input) {
+		c := input[end]
+		if c == '_' {
+			if end == start || end+1 >= len(input) || !isDigitChar(input[end+1], base) {
+				return end, end
+			}
+			end++
+			continue
+		}
+		if !isDigitChar(c, base) {
+			break
+		}
+		if digitVal(c) >= base {
+			return end, end
+		}
+		end++
+	}
+	return end, -1
+}
+
+// isDigitChar reports whether c belongs in a number of the given base. Decimal digits always do, so
+// that out of range digits like the 2 in 0b102 can be reported rather than ending the number early.
+func isDigitChar(c byte, base int) bool {
+	d := digitVal(c)
+	return d < 10 || (base == 16 && d < 16)
+}
+
+// excessDigit returns the position of the first digit after the first max digits of the mantissa of
+// the number starting at pos, or -1 if there are no more than max
+func excessDigit(input string, pos int, base int, max int) int {
+	pos, _ = scanSign(input, pos)
+	exponent := "eE"
+	if base != 10 {
+		pos += 2
+		exponent = "pP"
+	}
+	count := 0
+	for ; pos < len(input) && strings.IndexByte(exponent, input[pos]) < 0; pos++ {
+		if isDigitChar(input[pos], base) {
+			count++
+			if count > max {
+				return pos
+			}
+		}
+	}
+	return -1
+}
+
+func stripUnderscores(s string) string {
+	return strings.Replace(s, "_", "", -1)
+}
+
+func isDecimalDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// digitVal returns the value of a single hex digit, or 16 if c is not a hex digit
+func digitVal(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return 16
+}
+
+// ParseHex parses s as an unsigned hexadecimal number, eg "1F600", for grammars that decode their own
+// escapes. Both upper and lower case digits are accepted. It reports false if s is empty, has any other
+// characters, or is too long to fit in a rune.
+func ParseHex(s string) (rune, bool) {
+	if s == "" || len(s) > 8 {
+		return 0, false
+	}
+	r, ok := unhex(s)
+	if r < 0 {
+		// eight digits can overflow into the sign bit
+		return 0, false
+	}
+	return r, ok
+}
+
 func unhex(b string) (v rune, ok bool) {
 	for _, c := range b {
 		v <<= 4
@@ -344,3 +3016,30 @@ func IsValidRegexpDelimiter(r rune) (bool, rune) {
 	}
 	return false, -1
 }
+
+// IsValidRegexpDelimiterWith returns a delimiter check like IsValidRegexpDelimiter that also allows
+// the opening quotes in extra, closed by the quotes they map to. The extra pairs are checked first, so
+// they can also change how the built in quotes are closed.
+func IsValidRegexpDelimiterWith(extra map[rune]rune) func(rune) (bool, rune) {
+	pairs := make(map[rune]rune, len(extra))
+	for open, close := range extra {
+		pairs[open] = close
+	}
+	return func(r rune) (bool, rune) {
+		if close, exists := pairs[r]; exists {
+			return true, close
+		}
+		return IsValidRegexpDelimiter(r)
+	}
+}
+
+// IsValidRegexpDelimiterStrict is like IsValidRegexpDelimiter, but
+// rejects connector punctuation and dashes such as _ and -, which are
+// more likely to be part of an identifier or expression than the start
+// of a regexp.
+func IsValidRegexpDelimiterStrict(r rune) (bool, rune) {
+	if unicode.In(r, unicode.Pc, unicode.Pd) {
+		return false, -1
+	}
+	return IsValidRegexpDelimiter(r)
+}
@@ -1,7 +1,15 @@
 package goparsify
 
 import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
 
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +58,42 @@ func TestStringLit(t *testing.T) {
 		require.Equal(t, 0, p.Pos)
 	})
 
+	t.Run("test lone opening quote", func(t *testing.T) {
+		for _, input := range []string{`"`, ` '`} {
+			_, p := runParser(input, parser)
+			require.Equal(t, "offset "+strconv.Itoa(len(input))+": unterminated string literal, expected closing "+input[len(input)-1:], p.Error.Error())
+			opener, ok := p.Error.Opener()
+			require.True(t, ok)
+			require.Equal(t, len(input)-1, opener)
+		}
+
+		_, p := runParser(`'`, CharLit('\''))
+		require.Equal(t, "offset 1: unterminated char literal, expected closing '", p.Error.Error())
+	})
+
+	t.Run("test unterminated string positions", func(t *testing.T) {
+		_, p := runParser(`x = "hello`, Seq("x", "=", parser))
+		require.Equal(t, `offset 10: unterminated string literal, expected closing "`, p.Error.Error())
+		opener, ok := p.Error.Opener()
+		require.True(t, ok)
+		require.Equal(t, 4, opener)
+
+		_, p = runParser(`1`, parser)
+		_, ok = p.Error.Opener()
+		require.False(t, ok)
+
+		_, p = runParser(` """hello""`, TripleQuotedStringLit('"'))
+		require.Equal(t, `offset 11: unterminated string literal, expected closing """`, p.Error.Error())
+		opener, _ = p.Error.Opener()
+		require.Equal(t, 1, opener)
+
+		_, p = runParser(`{a}{b`, UnicodeRegexpReplaceLiteral())
+		require.Equal(t, `offset 5: unterminated regexp literal, expected closing }`, p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+		opener, _ = p.Error.Opener()
+		require.Equal(t, 3, opener)
+	})
+
 	t.Run("test escaping", func(t *testing.T) {
 		result, p := runParser(`"hello \"w\orld\""`, parser)
 		require.Equal(t, `hello "w\orld"`, result.Token)
@@ -86,111 +130,2928 @@ func TestStringLit(t *testing.T) {
 		require.Equal(t, "offset 9: expected [a-f0-9]{4}", p.Error.Error())
 		require.Equal(t, 0, p.Pos)
 	})
+
+	t.Run("test escaped unicode at end of input", func(t *testing.T) {
+		_, p := runParser(`"hello \ubeef`, parser)
+		require.Equal(t, "offset 13: unterminated string literal, expected closing \"", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
 }
 
-func TestUnicodeStringLiteral(t *testing.T) {
-	// TODO(db48x): I really ought to have a few more tests here
-	parser := UnicodeStringLiteral()
-	t.Run("test “” match", func(t *testing.T) {
-		result, p := runParser(`“hello”`, parser)
-		require.Equal(t, `hello`, result.Token)
-		require.Equal(t, "", p.Get())
+func TestStringLitBracedUnicodeEscapes(t *testing.T) {
+	parser := StringLit(`"'`)
+	t.Run("test braced unicode escape", func(t *testing.T) {
+		result, p := runParser(`"goblin \u{1F47A} \u{41}"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "goblin 👺 A", result.Token)
+		require.Equal(t, ``, p.Get())
 	})
-	t.Run("test ｢｣ match", func(t *testing.T) {
-		result, p := runParser(`｢“hello”｣`, parser)
-		require.Equal(t, `“hello”`, result.Token)
-		require.Equal(t, "", p.Get())
+
+	t.Run("test empty braces", func(t *testing.T) {
+		_, p := runParser(`"\u{}"`, parser)
+		require.Equal(t, "offset 4: expected [a-f0-9]", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test missing closing brace", func(t *testing.T) {
+		_, p := runParser(`"\u{41"`, parser)
+		require.Equal(t, "offset 6: expected }", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test too many digits", func(t *testing.T) {
+		_, p := runParser(`"\u{0000041}"`, parser)
+		require.Equal(t, "offset 10: expected }", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test underscores", func(t *testing.T) {
+		result, p := runParser(`"\u{1_F600} \u{0_0_4_1}"`, parser)
+		require.Equal(t, "😀 A", result.Token)
+		require.Equal(t, ``, p.Get())
+
+		_, p = runParser(`"\u{0_000_041}"`, parser)
+		require.Equal(t, "offset 12: expected }", p.Error.Error())
+	})
+
+	t.Run("test misplaced underscores", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			`"\u{_1F600}"`:  "offset 4: expected [a-f0-9]",
+			`"\u{1F600_}"`:  "offset 9: expected [a-f0-9]",
+			`"\u{1F__600}"`: "offset 6: expected [a-f0-9]",
+		} {
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+
+	t.Run("test code point out of range", func(t *testing.T) {
+		_, p := runParser(`"\u{110000}"`, parser)
+		require.Equal(t, "offset 4: expected valid unicode code point", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
 	})
 }
 
-func TestUnhex(t *testing.T) {
-	tests := map[int64]string{
-		0xF:        "F",
-		0x5:        "5",
-		0xFF:       "FF",
-		0xFFF:      "FFF",
-		0xA4B:      "a4b",
-		0xFFFF:     "FFFF",
-		0xBEEFCAFE: "beeFCAfe",
+func TestStringLitSurrogatePairs(t *testing.T) {
+	parser := StringLit(`"`)
+	t.Run("test pair", func(t *testing.T) {
+		result, p := runParser(`"\uD83D\uDE00 \ud834\udd1e"`, parser)
+		require.Equal(t, "😀 𝄞", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test lone high surrogate", func(t *testing.T) {
+		_, p := runParser(`"a\uD83D b"`, parser)
+		require.Equal(t, "offset 2: expected surrogate pair", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test lone low surrogate", func(t *testing.T) {
+		_, p := runParser(`"a\uDE00\uD83D"`, parser)
+		require.Equal(t, "offset 2: expected surrogate pair", p.Error.Error())
+	})
+}
+
+func TestStringLitHexEscapes(t *testing.T) {
+	parser := StringLit(`"'`)
+	t.Run("test hex escape", func(t *testing.T) {
+		result, p := runParser(`"\x41\x62c"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "Abc", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test invalid hex escape", func(t *testing.T) {
+		_, p := runParser(`"hello \xzz"`, parser)
+		require.Equal(t, "offset 9: expected [a-f0-9]", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test incomplete hex escape", func(t *testing.T) {
+		_, p := runParser(`"hello \x4`, parser)
+		require.Equal(t, "offset 9: expected [a-f0-9]{2}", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestStringLitLongUnicodeEscapes(t *testing.T) {
+	parser := StringLit(`"'`)
+	t.Run("test long unicode escape", func(t *testing.T) {
+		result, p := runParser(`"goblin \U0001F47A!"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "goblin 👺!", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test code point out of range", func(t *testing.T) {
+		_, p := runParser(`"\U00110000"`, parser)
+		require.Equal(t, "offset 3: expected valid unicode code point", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test surrogate code point", func(t *testing.T) {
+		_, p := runParser(`"\U0000D800"`, parser)
+		require.Equal(t, "offset 3: expected valid unicode code point", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test incomplete long unicode escape", func(t *testing.T) {
+		_, p := runParser(`"\U0001F4"`, parser)
+		require.Equal(t, "offset 3: expected [a-f0-9]{8}", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestAnyStringLit(t *testing.T) {
+	parser := AnyStringLit()
+	t.Run("test each quote", func(t *testing.T) {
+		for _, quote := range []rune{'\'', '"', '`'} {
+			q := string(quote)
+			result, p := runParser(" "+q+`a\tb`+q+" rest", parser)
+			require.False(t, p.Errored(), q)
+			require.Equal(t, "a\tb", result.Token, q)
+			require.Equal(t, quote, result.Opener, q)
+			require.Equal(t, quote, result.Closer, q)
+			require.Equal(t, " rest", p.Get(), q)
+		}
+	})
+
+	t.Run("test other quotes are part of the string", func(t *testing.T) {
+		result, _ := runParser("`it's \"quoted\"`", parser)
+		require.Equal(t, `it's "quoted"`, result.Token)
+	})
+
+	t.Run("test options", func(t *testing.T) {
+		result, _ := runParser(`'a''b'`, AnyStringLit(DoubledQuotes(), KeepQuotes()))
+		require.Equal(t, "a'b", result.Token)
+		require.Equal(t, `'a''b'`, result.Raw)
+	})
+
+	t.Run("test not a string", func(t *testing.T) {
+		_, p := runParser(`«a»`, parser)
+		require.Equal(t, "offset 0: expected '\"`", p.Error.Error())
+	})
+}
+
+func TestQuotedIdentLit(t *testing.T) {
+	parser := QuotedIdentLit('[', ']')
+	t.Run("test names", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			`[My Column]`:  "My Column",
+			`[a]]b]`:       "a]b",
+			`[]]]`:         "]",
+			`[say "hi"\n]`: `say "hi"\n`,
+			`[select]`:     "select",
+		} {
+			result, p := runParser(" "+input+" rest", parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Token, input)
+			require.Equal(t, '[', result.Opener, input)
+			require.Equal(t, ']', result.Closer, input)
+			require.Equal(t, 2, result.Start, input)
+			require.Equal(t, len(input)+1, result.End, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test backticks", func(t *testing.T) {
+		result, _ := runParser("`order``s`", QuotedIdentLit('`', '`'))
+		require.Equal(t, "order`s", result.Token)
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		_, p := runParser(`"name"`, parser)
+		require.Equal(t, "offset 0: expected [", p.Error.Error())
+
+		_, p = runParser(`[]`, parser)
+		require.Equal(t, "offset 1: expected identifier", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		_, p = runParser(`[name]] `, parser)
+		require.Equal(t, "offset 8: unterminated quoted identifier, expected closing ]", p.Error.Error())
+		opener, ok := p.Error.Opener()
+		require.True(t, ok)
+		require.Equal(t, 0, opener)
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestLiteralSpans(t *testing.T) {
+	// Start is where the contents begin and End is just after the closing delimiter
+	tests := []struct {
+		name       string
+		input      string
+		parser     Parser
+		start, end int
+	}{
+		{"string", ` "hi\n" `, StringLit(`"`), 2, 7},
+		{"empty string", `""`, StringLit(`"`), 1, 2},
+		{"raw string", " `hi` ", RawStringLit("`"), 2, 5},
+		{"char", ` 'a' `, CharLit('\''), 2, 4},
+		{"triple quoted", ` """hi""" `, TripleQuotedStringLit('"'), 4, 9},
+		{"heredoc", "<<EOF\nhi\nEOF\n", HeredocLit(), 6, 12},
+		{"byte string", ` b"hi" `, ByteStringLit(), 3, 6},
+		{"unicode string", ` «hi» `, UnicodeStringLiteral(), 3, 7},
+		{"regexp match", ` /a+/ `, UnicodeRegexpMatchLiteral(), 2, 5},
+		{"regexp replace", ` /a+/b/ `, UnicodeRegexpReplaceLiteral(), 2, 7},
+		{"number", ` -12 `, NumberLit(), 1, 4},
+		{"duration", ` 1h30m `, DurationLit(), 1, 6},
+		{"bool", ` true `, BoolLit([]string{"true"}, []string{"false"}), 1, 5},
+		{"concatenated strings", ` "a" "b" `, ConcatStringLits(`"`), 2, 8},
 	}
-	for expected, input := range tests {
-		t.Run(input, func(t *testing.T) {
-			r, ok := unhex(input)
-			require.True(t, ok)
-			require.EqualValues(t, expected, r)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, p := runParser(test.input, test.parser)
+			require.False(t, p.Errored(), p.Error.Error())
+			require.Equal(t, test.start, result.Start)
+			require.Equal(t, test.end, result.End)
+			require.Equal(t, test.end, p.Pos)
 		})
 	}
+}
 
-	t.Run("Fails on non hex chars", func(t *testing.T) {
-		_, ok := unhex("hello")
-		require.False(t, ok)
+func TestStringLitAnyQuote(t *testing.T) {
+	parser := StringLit("")
+	t.Run("test any delimiter", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			`|hello|`:       "hello",
+			`"hello"`:       "hello",
+			`#a\#b#`:        "a#b",
+			`«hello«`:       "hello",
+			`xhello worldx`: "hello world",
+		} {
+			result, p := runParser(input+" rest", parser)
+			require.Equal(t, expected, result.Token, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test digit", func(t *testing.T) {
+		_, p := runParser("1hello1", parser)
+		require.Equal(t, "offset 0: expected string delimiter", p.Error.Error())
+	})
+
+	t.Run("test empty input", func(t *testing.T) {
+		_, p := runParser("   ", parser)
+		require.Equal(t, "offset 3: expected string delimiter", p.Error.Error())
+	})
+
+	t.Run("test unterminated", func(t *testing.T) {
+		_, p := runParser("|hello", parser)
+		require.Equal(t, "offset 6: unterminated string literal, expected closing |", p.Error.Error())
 	})
 }
 
-func TestNumberLit(t *testing.T) {
-	parser := NumberLit()
-	t.Run("test int", func(t *testing.T) {
-		result, p := runParser("1234", parser)
-		require.Equal(t, int64(1234), result.Result)
+func TestStringLitKeepQuotes(t *testing.T) {
+	t.Run("test keeping quotes", func(t *testing.T) {
+		result, p := runParser(` "hello\tworld" rest`, StringLit(`"'`, KeepQuotes()))
+		require.Equal(t, "hello\tworld", result.Token)
+		require.Equal(t, `"hello\tworld"`, result.Raw)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test any quote", func(t *testing.T) {
+		result, _ := runParser(`|a\|b|`, StringLit("", KeepQuotes()))
+		require.Equal(t, "a|b", result.Token)
+		require.Equal(t, `|a\|b|`, result.Raw)
+	})
+
+	t.Run("test custom string literal", func(t *testing.T) {
+		result, _ := runParser(`«hello»`, CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, KeepQuotes()))
+		require.Equal(t, "hello", result.Token)
+		require.Equal(t, `«hello»`, result.Raw)
+	})
+
+	t.Run("test default", func(t *testing.T) {
+		result, _ := runParser(`"hello"`, StringLit(`"`))
+		require.Equal(t, "", result.Raw)
+	})
+}
+
+func TestByteStringLit(t *testing.T) {
+	parser := ByteStringLit()
+	t.Run("test bytes", func(t *testing.T) {
+		result, p := runParser(` b"a\xFF\x00\n" rest`, parser)
+		require.Equal(t, []byte{'a', 0xFF, 0, '\n'}, result.Result)
+		require.Equal(t, "a\xFF\x00\n", result.Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test single quotes", func(t *testing.T) {
+		result, _ := runParser(`B'it\'s'`, parser)
+		require.Equal(t, []byte("it's"), result.Result)
+	})
+
+	t.Run("test no unicode escapes", func(t *testing.T) {
+		result, _ := runParser(`b"\u0041"`, parser)
+		require.Equal(t, []byte(`\u0041`), result.Result)
+
+		_, p := runParser(`b"\u0041"`, ByteStringLit(StrictEscapes()))
+		require.Equal(t, `offset 2: expected escape \" \\ \/ \a \b \f \n \r \t \v \x`, p.Error.Error())
+	})
+
+	t.Run("test non ascii", func(t *testing.T) {
+		_, p := runParser(`b"café"`, parser)
+		require.Equal(t, "offset 5: expected ASCII character", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		result, _ := runParser(`b"café"`, ByteStringLit(NonASCIIBytes()))
+		require.Equal(t, []byte("café"), result.Result)
+	})
+
+	t.Run("test missing prefix", func(t *testing.T) {
+		_, p := runParser(`"hello"`, parser)
+		require.Equal(t, `offset 0: expected b"`, p.Error.Error())
+	})
+}
+
+func TestConcatStringLits(t *testing.T) {
+	parser := ConcatStringLits(`"`)
+	t.Run("test concatenation", func(t *testing.T) {
+		result, p := runParser(`"foo" "bar\n"
+	"baz"; rest`, parser)
+		require.Equal(t, "foobar\nbaz", result.Token)
+		require.Len(t, result.Child, 3)
+		require.Equal(t, "foo", result.Child[0].Token)
+		require.Equal(t, 1, result.Child[0].Start)
+		require.Equal(t, "bar\n", result.Child[1].Token)
+		require.Equal(t, 7, result.Child[1].Start)
+		require.Equal(t, "baz", result.Child[2].Token)
+		require.Equal(t, 16, result.Child[2].Start)
+		require.Equal(t, "; rest", p.Get())
+	})
+
+	t.Run("test single literal", func(t *testing.T) {
+		result, p := runParser(`"foo" bar`, parser)
+		require.Equal(t, "foo", result.Token)
+		require.Len(t, result.Child, 1)
+		require.Equal(t, " bar", p.Get())
+	})
+
+	t.Run("test no literal", func(t *testing.T) {
+		_, p := runParser(`foo`, parser)
+		require.Equal(t, `offset 0: expected "`, p.Error.Error())
+	})
+}
+
+func TestRawStringLit(t *testing.T) {
+	parser := RawStringLit("`'")
+	t.Run("test match", func(t *testing.T) {
+		result, p := runParser("`hello \\n\\u0041`", parser)
+		require.Equal(t, `hello \n\u0041`, result.Token)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("test float", func(t *testing.T) {
-		result, p := runParser("12.34", parser)
-		require.Equal(t, 12.34, result.Result)
+	t.Run("test unicode chars", func(t *testing.T) {
+		result, p := runParser(`'hello 👺' world`, parser)
+		require.Equal(t, `hello 👺`, result.Token)
+		require.Equal(t, ` world`, p.Get())
+	})
+
+	t.Run("test backslash before closer", func(t *testing.T) {
+		result, p := runParser(`'C:\'`, parser)
+		require.Equal(t, `C:\`, result.Token)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("test negative float", func(t *testing.T) {
-		result, p := runParser("-12.34", parser)
-		require.Equal(t, -12.34, result.Result)
+	t.Run("test non match", func(t *testing.T) {
+		_, p := runParser(`"hello"`, parser)
+		require.Equal(t, "`'", p.Error.expected)
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test unterminated string", func(t *testing.T) {
+		_, p := runParser("`hello", parser)
+		require.Equal(t, "`", p.Error.expected)
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestCharLit(t *testing.T) {
+	parser := CharLit('\'')
+	t.Run("test char", func(t *testing.T) {
+		result, p := runParser(`'a'`, parser)
+		require.Equal(t, 'a', result.Result)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("without leading zero", func(t *testing.T) {
-		result, p := runParser("-.34", parser)
-		require.Equal(t, -.34, result.Result)
+	t.Run("test unicode char", func(t *testing.T) {
+		result, p := runParser(`'👺'`, parser)
+		require.Equal(t, '👺', result.Result)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("scientific notation", func(t *testing.T) {
-		result, p := runParser("12.34e3", parser)
-		require.Equal(t, 12.34e3, result.Result)
+	t.Run("test escapes", func(t *testing.T) {
+		result, _ := runParser(`'\n'`, parser)
+		require.Equal(t, '\n', result.Result)
+		result, _ = runParser(`'\u0041'`, parser)
+		require.Equal(t, 'A', result.Result)
+		result, _ = runParser(`'\''`, parser)
+		require.Equal(t, '\'', result.Result)
+		result, _ = runParser(`'\xff'`, parser)
+		require.Equal(t, rune(0xff), result.Result)
+	})
+
+	t.Run("test invalid UTF-8", func(t *testing.T) {
+		_, p := runParser("'\xff'", parser)
+		require.Equal(t, "offset 1: expected valid UTF-8", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test byte escapes aren't decoded", func(t *testing.T) {
+		_, p := runParser(`'\xc3\xa9'`, parser)
+		require.Equal(t, "offset 1: expected single character", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test replacement character", func(t *testing.T) {
+		result, p := runParser("'\uFFFD'", parser)
+		require.Equal(t, '\uFFFD', result.Result)
+		require.Equal(t, "", p.Get())
+		result, p = runParser(`'\uFFFD'`, parser)
+		require.Equal(t, '\uFFFD', result.Result)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("scientific notation without decimal", func(t *testing.T) {
-		result, p := runParser("34e3", parser)
-		require.Equal(t, 34e3, result.Result)
+	t.Run("test empty", func(t *testing.T) {
+		_, p := runParser(`''`, parser)
+		require.Equal(t, "offset 1: expected single character", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test too many characters", func(t *testing.T) {
+		_, p := runParser(`'ab'`, parser)
+		require.Equal(t, "offset 1: expected single character", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test wrong quote", func(t *testing.T) {
+		_, p := runParser(`"a"`, parser)
+		require.Equal(t, "offset 0: expected '", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestTripleQuotedStringLit(t *testing.T) {
+	parser := TripleQuotedStringLit('"')
+	t.Run("test match", func(t *testing.T) {
+		result, p := runParser(`"""hello"""`, parser)
+		require.Equal(t, `hello`, result.Token)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("scientific notation negative power", func(t *testing.T) {
-		result, p := runParser("34e-3", parser)
-		require.Equal(t, 34e-3, result.Result)
+	t.Run("test embedded quotes and newlines", func(t *testing.T) {
+		result, p := runParser("\"\"\"say \"hi\"\n\"\"twice\"\"\" rest", parser)
+		require.Equal(t, "say \"hi\"\n\"\"twice", result.Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test escapes", func(t *testing.T) {
+		result, p := runParser(`"""a\tb\"""c"""`, parser)
+		require.Equal(t, "a\tb\"\"\"c", result.Token)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("negative scientific notation negative power", func(t *testing.T) {
-		result, p := runParser("-.34e-3", parser)
-		require.Equal(t, -.34e-3, result.Result)
+	t.Run("test single quotes are not enough", func(t *testing.T) {
+		_, p := runParser(`"hello"`, parser)
+		require.Equal(t, `"""`, p.Error.expected)
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test unterminated string", func(t *testing.T) {
+		_, p := runParser(`"""hello""`, parser)
+		require.Equal(t, `"""`, p.Error.expected)
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestHeredocLit(t *testing.T) {
+	parser := HeredocLit()
+	t.Run("test match", func(t *testing.T) {
+		result, p := runParser("<<EOF\nhello\n  world\nEOF\nrest", parser)
+		require.Equal(t, "hello\n  world\n", result.Token)
+		require.Equal(t, "\nrest", p.Get())
+	})
+
+	t.Run("test terminator at end of input", func(t *testing.T) {
+		result, p := runParser("<<END\r\nhello\r\nEND", parser)
+		require.Equal(t, "hello\r\n", result.Token)
 		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("partial match", func(t *testing.T) {
-		result, p := runParser("-1.34foo", parser)
-		require.Equal(t, -1.34, result.Result)
-		require.Equal(t, "foo", p.Get())
+	t.Run("test empty body", func(t *testing.T) {
+		result, p := runParser("<<EOF\nEOF", parser)
+		require.Equal(t, "", result.Token)
+		require.Equal(t, "", p.Get())
 	})
 
-	t.Run("non matching string", func(t *testing.T) {
-		_, p := runParser("foo", parser)
-		require.Equal(t, "offset 0: expected number", p.Error.Error())
+	t.Run("test terminator must be the whole line", func(t *testing.T) {
+		result, p := runParser("<<EOF\n EOF\nEOF2\nEOF", parser)
+		require.Equal(t, " EOF\nEOF2\n", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test indented terminator", func(t *testing.T) {
+		result, p := runParser("<<-EOF\n\thello\n\t\tEOF", parser)
+		require.Equal(t, "\thello\n", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test indented terminator needs <<-", func(t *testing.T) {
+		_, p := runParser("<<EOF\nhello\n\tEOF", parser)
+		require.Equal(t, "offset 16: unterminated heredoc, expected closing EOF", p.Error.Error())
 		require.Equal(t, 0, p.Pos)
 	})
 
-	t.Run("invalid number", func(t *testing.T) {
-		_, p := runParser("-.", parser)
+	t.Run("test missing identifier", func(t *testing.T) {
+		_, p := runParser("<< EOF\nEOF", parser)
+		require.Equal(t, "offset 2: expected heredoc identifier", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test text after identifier", func(t *testing.T) {
+		_, p := runParser("<<EOF foo\nEOF", parser)
+		require.Equal(t, "offset 5: expected newline", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestUnicodeStringLiteral(t *testing.T) {
+	// TODO(db48x): I really ought to have a few more tests here
+	parser := UnicodeStringLiteral()
+	t.Run("test “” match", func(t *testing.T) {
+		result, p := runParser(`“hello”`, parser)
+		require.Equal(t, `hello`, result.Token)
+		require.Equal(t, "", p.Get())
+	})
+	t.Run("test ｢｣ match", func(t *testing.T) {
+		result, p := runParser(`｢“hello”｣`, parser)
+		require.Equal(t, `“hello”`, result.Token)
+		require.Equal(t, "", p.Get())
+	})
+	t.Run("test delimiters are reported", func(t *testing.T) {
+		result, _ := runParser(`「hello」`, parser)
+		require.Equal(t, '「', result.Opener)
+		require.Equal(t, '」', result.Closer)
+
+		result, _ = runParser(`/hello/`, parser)
+		require.Equal(t, '/', result.Opener)
+		require.Equal(t, '/', result.Closer)
+	})
+	t.Run("test unterminated string", func(t *testing.T) {
+		_, p := runParser(`“hello`, parser)
+		require.Equal(t, `”`, p.Error.expected)
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestCustomStringLiteralOctalEscapes(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, OctalEscapes())
+	t.Run("test octal escapes", func(t *testing.T) {
+		result, p := runParser(`"\101\12\0z"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "A\n\x00z", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test octal escape stops after three digits", func(t *testing.T) {
+		result, p := runParser(`"\1011"`, parser)
+		require.Equal(t, "A1", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test octal escape out of range", func(t *testing.T) {
+		_, p := runParser(`"\777"`, parser)
+		require.Equal(t, `offset 2: expected octal escape up to \377`, p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test octal escapes are off by default", func(t *testing.T) {
+		result, p := runParser(`"\101"`, UnicodeStringLiteral())
+		require.Equal(t, `\101`, result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+}
+
+func TestCustomStringLiteralLineContinuations(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, LineContinuations())
+	t.Run("test line continuation", func(t *testing.T) {
+		result, p := runParser("\"hello \\\n  world\"", parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "hello   world", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test crlf line continuation", func(t *testing.T) {
+		result, p := runParser("\"hello \\\r\nworld\"", parser)
+		require.Equal(t, "hello world", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test line continuations are off by default", func(t *testing.T) {
+		result, p := runParser("\"hello \\\nworld\"", UnicodeStringLiteral())
+		require.Equal(t, "hello \\\nworld", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+}
+
+func TestCustomStringLiteralStrictEscapes(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, StrictEscapes())
+	t.Run("test known escapes", func(t *testing.T) {
+		result, p := runParser(`"a\tb\"c\u0041"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "a\tb\"cA", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test unknown escape", func(t *testing.T) {
+		_, p := runParser(`"hello \q"`, parser)
+		require.Equal(t, `offset 7: expected escape \" \\ \/ \a \b \f \n \r \t \v \u \U \x`, p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test escaped backslash and slash", func(t *testing.T) {
+		for input, expected := range map[string]string{`"\\"`: `\`, `"a\\b\/c"`: `a\b/c`} {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Token, input)
+			require.Equal(t, ``, p.Get(), input)
+		}
+	})
+
+	t.Run("test surrogate pair", func(t *testing.T) {
+		result, p := runParser(`"a\uD83D\uDE00b"`, parser)
+		require.Equal(t, "a😀b", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test lone surrogates", func(t *testing.T) {
+		for _, input := range []string{`"ab\uD800"`, `"ab\uD800c"`, `"ab\uD800\u0041"`, `"ab\uDC00"`, `"ab\uDC00\uDC00"`, `"ab\uD800\uD800"`, `"ab\uD800\uDC0"`} {
+			_, p := runParser(input, parser)
+			require.Equal(t, "offset 3: expected surrogate pair", p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+
+	t.Run("test unknown escapes are lenient by default", func(t *testing.T) {
+		result, p := runParser(`"hello \q"`, StringLit(`"`))
+		require.Equal(t, `hello \q`, result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+}
+
+func TestHexEscape(t *testing.T) {
+	t.Run("digits ending at end of input", func(t *testing.T) {
+		ps := NewState(`\ubeef`)
+		r, ok := hexEscape(ps, 2, 4)
+		require.True(t, ok)
+		require.Equal(t, '\ubeef', r)
+		require.False(t, ps.Errored())
+	})
+
+	t.Run("digits past end of input", func(t *testing.T) {
+		ps := NewState(`\ubee`)
+		_, ok := hexEscape(ps, 2, 4)
+		require.False(t, ok)
+		require.Equal(t, "offset 2: expected [a-f0-9]{4}", ps.Error.Error())
+	})
+}
+
+func TestCustomStringLiteralEscapeWith(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, EscapeWith('~'), StrictEscapes())
+	t.Run("test custom escapes", func(t *testing.T) {
+		result, p := runParser(`"a~tb~"c\d~u0041"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "a\tb\"c\\dA", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test unknown escape", func(t *testing.T) {
+		_, p := runParser(`"~q"`, parser)
+		require.Equal(t, `offset 1: expected escape ~" ~~ ~/ ~a ~b ~f ~n ~r ~t ~v ~u ~U ~x`, p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestCustomStringLiteralEscapeFunc(t *testing.T) {
+	// \N{name} escapes as in Python, and \e for a whole ANSI reset sequence
+	names := map[string]string{"SNOWMAN": "☃", "BULLET": "•"}
+	escapeFunc := func(ps *State, after rune) (string, int, bool) {
+		switch after {
+		case 'e':
+			return "\x1b[0m", 1, true
+		case 'N':
+			rest := ps.Get()
+			end := strings.IndexByte(rest, '}')
+			if !strings.HasPrefix(rest, "N{") || end < 0 {
+				ps.ErrorHere("{name}")
+				return "", 0, false
+			}
+			name, ok := names[rest[2:end]]
+			if !ok {
+				ps.ErrorHere("character name")
+				return "", 0, false
+			}
+			return name, end + 1, true
+		}
+		return "", 0, false
+	}
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, EscapeFunc(escapeFunc))
+
+	t.Run("test function escapes", func(t *testing.T) {
+		result, p := runParser(`"\N{SNOWMAN} \N{BULLET}\e"`, parser)
+		require.Equal(t, "☃ •\x1b[0m", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test falls back to the map", func(t *testing.T) {
+		result, _ := runParser(`"a\tb\u0041\""`, parser)
+		require.Equal(t, "a\tbA\"", result.Token)
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		_, p := runParser(`"\N{COMET}"`, parser)
+		require.Equal(t, "offset 2: expected character name", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		_, p = runParser(`"\N"`, parser)
+		require.Equal(t, "offset 2: expected {name}", p.Error.Error())
+	})
+}
+
+func TestCustomStringLiteralNamedEscapes(t *testing.T) {
+	names := map[string]string{"null": "\x00", "newline": "\n", "new": "NEW", "amp;": "&"}
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, NamedEscapes(names))
+
+	t.Run("test named escapes", func(t *testing.T) {
+		result, p := runParser(`"a\nullb\amp;c" rest`, parser)
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Equal(t, "a\x00b&c", result.Token)
+		require.Equal(t, ` rest`, p.Get())
+	})
+
+	t.Run("test longest name wins", func(t *testing.T) {
+		result, _ := runParser(`"\newline\newt"`, parser)
+		require.Equal(t, "\nNEWt", result.Token)
+	})
+
+	t.Run("test falls back to single character escapes", func(t *testing.T) {
+		result, _ := runParser(`"\n\t\u0041"`, parser)
+		require.Equal(t, "\n\tA", result.Token)
+	})
+
+	t.Run("test names can't be changed afterwards", func(t *testing.T) {
+		names["null"] = "nil"
+		defer func() { names["null"] = "\x00" }()
+		result, _ := runParser(`"\null"`, parser)
+		require.Equal(t, "\x00", result.Token)
+	})
+}
+
+func TestCustomStringLiteralFoldWhitespace(t *testing.T) {
+	parser := StringLit(`"`, FoldWhitespace())
+	t.Run("test runs are collapsed", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"a\tb":                       "a b",
+			"a \t  b":                    "a b",
+			"first line\r\nsecond line":  "first line second line",
+			"para one\n\n\n  para two\n": "para one para two ",
+			"\n\tindented\n":             " indented ",
+			"no\u00a0break\u2003em":      "no break em",
+			"plain":                      "plain",
+		} {
+			result, p := runParser(`"`+input+`" rest`, parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Token, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test escaped whitespace is kept", func(t *testing.T) {
+		result, _ := runParser(`"a\n\n b \t"`, parser)
+		require.Equal(t, "a\n\n b \t", result.Token)
+	})
+
+	t.Run("test max length counts the folded space once", func(t *testing.T) {
+		result, p := runParser("\"ab \n\t cd\"", StringLit(`"`, FoldWhitespace(), MaxLength(5)))
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Equal(t, "ab cd", result.Token)
+	})
+
+	t.Run("test control characters are still errors", func(t *testing.T) {
+		parser := StringLit(`"`, FoldWhitespace(), NoControlChars())
+		_, p := runParser("\"a \n b\"", parser)
+		require.Equal(t, "offset 3: expected escaped control character", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		result, p := runParser(`"a   b"`, parser)
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Equal(t, "a b", result.Token)
+	})
+
+	t.Run("test off by default", func(t *testing.T) {
+		result, _ := runParser("\"a \n b\"", StringLit(`"`))
+		require.Equal(t, "a \n b", result.Token)
+	})
+}
+
+func TestCustomStringLiteralDoubledQuotes(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, DoubledQuotes())
+	t.Run("test sql style", func(t *testing.T) {
+		result, p := runParser(`'it''s' rest`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "it's", result.Token)
+		require.Equal(t, ` rest`, p.Get())
+	})
+
+	t.Run("test csv style", func(t *testing.T) {
+		result, p := runParser(`"she said ""hi"""`, parser)
+		require.Equal(t, `she said "hi"`, result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test empty string", func(t *testing.T) {
+		result, p := runParser(`"" ""`, parser)
+		require.Equal(t, ``, result.Token)
+		require.Equal(t, ` ""`, p.Get())
+	})
+
+	t.Run("test doubled quotes are off by default", func(t *testing.T) {
+		result, p := runParser(`'it''s'`, UnicodeStringLiteral())
+		require.Equal(t, "it", result.Token)
+		require.Equal(t, `'s'`, p.Get())
+	})
+}
+
+func TestCustomStringLiteralNoControlChars(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, NoControlChars())
+	t.Run("test escaped control chars", func(t *testing.T) {
+		result, p := runParser(`"a\nb\tc"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "a\nb\tc", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test raw newline", func(t *testing.T) {
+		_, p := runParser("\"a\nb\"", parser)
+		require.Equal(t, "offset 2: expected escaped control character", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test raw tab", func(t *testing.T) {
+		_, p := runParser("\"a\tb\"", parser)
+		require.Equal(t, "offset 2: expected escaped control character", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test raw newlines are allowed by default", func(t *testing.T) {
+		result, p := runParser("\"a\nb\"", UnicodeStringLiteral())
+		require.Equal(t, "a\nb", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+}
+
+func TestCustomStringLiteralRecordEscapes(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, RecordEscapes())
+	t.Run("test escape positions", func(t *testing.T) {
+		result, p := runParser(`"a\tb\u0041c\""`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "a\tbAc\"", result.Token)
+		require.Equal(t, []Result{
+			{Token: "\t", Start: 2, End: 4},
+			{Token: "A", Start: 5, End: 11},
+			{Token: `"`, Start: 12, End: 14},
+		}, result.Child)
+	})
+
+	t.Run("test no escapes", func(t *testing.T) {
+		result, _ := runParser(`"abc"`, parser)
+		require.Nil(t, result.Child)
+	})
+
+	t.Run("test escapes are not recorded by default", func(t *testing.T) {
+		result, _ := runParser(`"a\tb"`, UnicodeStringLiteral())
+		require.Nil(t, result.Child)
+	})
+}
+
+func TestCustomStringLiteralRecoverEscapes(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, RecoverEscapes())
+	t.Run("test recovering", func(t *testing.T) {
+		result, p := runParser(`"a\uZZZZb\u41\n\x4" rest`, parser)
+		require.Equal(t, "a\uFFFDZZZZb\uFFFD41\n\uFFFD4", result.Token)
+		require.Equal(t, " rest", p.Get())
+		require.False(t, p.Errored())
+
+		require.Len(t, result.Child, 3)
+		require.Equal(t, `\u`, result.Child[0].Token)
+		require.Equal(t, 2, result.Child[0].Start)
+		require.Equal(t, 4, result.Child[0].End)
+		require.Equal(t, "offset 4: expected [a-f0-9]", result.Child[0].Result.(*Error).Error())
+		require.Equal(t, 9, result.Child[1].Start)
+		require.Equal(t, 15, result.Child[2].Start)
+		require.Equal(t, "offset 17: expected [a-f0-9]", result.Child[2].Result.(*Error).Error())
+	})
+
+	t.Run("test valid escapes are not recorded", func(t *testing.T) {
+		result, _ := runParser(`"a\u0041\n"`, parser)
+		require.Equal(t, "aA\n", result.Token)
+		require.Len(t, result.Child, 0)
+	})
+
+	t.Run("test unterminated string still fails", func(t *testing.T) {
+		_, p := runParser(`"a\uZZ`, parser)
+		require.Equal(t, `offset 6: unterminated string literal, expected closing "`, p.Error.Error())
+	})
+
+	t.Run("test fails by default", func(t *testing.T) {
+		_, p := runParser(`"a\uZZZZb"`, UnicodeStringLiteral())
+		require.Equal(t, "offset 4: expected [a-f0-9]", p.Error.Error())
+	})
+}
+
+func TestCustomStringLiteralCountEscapes(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, CountEscapes())
+	t.Run("test escapes", func(t *testing.T) {
+		result, p := runParser(`"a\tb\u0041c\"\q"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, 4, result.Escapes)
+	})
+
+	t.Run("test no escapes", func(t *testing.T) {
+		result := Result{Escapes: 3}
+		parser(NewState(`"abc"`), &result)
+		require.Equal(t, 0, result.Escapes)
+	})
+
+	t.Run("test escapes are not counted by default", func(t *testing.T) {
+		result, _ := runParser(`"a\tb"`, UnicodeStringLiteral())
+		require.Equal(t, 0, result.Escapes)
+	})
+}
+
+func TestCustomStringLiteralNestedDelimiters(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, NestedDelimiters())
+	t.Run("test nested brackets", func(t *testing.T) {
+		result, p := runParser(`{a{b}c} rest`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "a{b}c", result.Token)
+		require.Equal(t, ` rest`, p.Get())
+	})
+
+	t.Run("test deeply nested brackets", func(t *testing.T) {
+		result, p := runParser(`「a「b「c」」」`, parser)
+		require.Equal(t, "a「b「c」」", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test escaped brackets are not counted", func(t *testing.T) {
+		result, p := runParser(`{a\{b}`, parser)
+		require.Equal(t, "a\\{b", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test unbalanced brackets", func(t *testing.T) {
+		_, p := runParser(`{a{b}`, parser)
+		require.Equal(t, "}", p.Error.expected)
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test same opener and closer", func(t *testing.T) {
+		result, p := runParser(`/a/b/`, parser)
+		require.Equal(t, "a", result.Token)
+		require.Equal(t, `b/`, p.Get())
+	})
+
+	t.Run("test nesting limit", func(t *testing.T) {
+		limited := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, NestedDelimiters(), MaxNesting(2))
+		result, p := runParser(`{a{b{c}}}`, limited)
+		require.Equal(t, "a{b{c}}", result.Token)
+		require.Equal(t, ``, p.Get())
+
+		_, p = runParser(`{a{b{c{d}}}}`, limited)
+		require.Equal(t, "offset 6: expected at most 2 nested {", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test default nesting limit", func(t *testing.T) {
+		input := "(" + strings.Repeat("(", 1000) + strings.Repeat(")", 1000) + ")"
+		result, p := runParser(input, parser)
+		require.Len(t, result.Token, 2000)
+		require.Equal(t, ``, p.Get())
+
+		input = "(" + strings.Repeat("(", 1001) + strings.Repeat(")", 1001) + ")"
+		_, p = runParser(input, parser)
+		require.Equal(t, "offset 1001: expected at most 1000 nested (", p.Error.Error())
+	})
+
+	t.Run("test brackets do not nest by default", func(t *testing.T) {
+		result, p := runParser(`{a{b}c}`, UnicodeStringLiteral())
+		require.Equal(t, "a{b", result.Token)
+		require.Equal(t, `c}`, p.Get())
+	})
+}
+
+func TestCustomStringLiteralMaxScan(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, MaxScan(5))
+	t.Run("test short enough", func(t *testing.T) {
+		result, p := runParser(`"héllo"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "héllo", result.Token)
+	})
+
+	t.Run("test escapes count as written", func(t *testing.T) {
+		result, p := runParser(`"a\tb"`, parser)
+		require.Equal(t, "a\tb", result.Token)
+
+		_, p = runParser(`"\u0041"`, parser)
+		require.Equal(t, "offset 7: expected \" within 5 characters", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test unterminated", func(t *testing.T) {
+		_, p := runParser(`"hello world`+strings.Repeat(" ", 1000), parser)
+		require.Equal(t, "offset 6: expected \" within 5 characters", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestStringLitEscapedCloser(t *testing.T) {
+	// the buffer is sized up to the first closer, which here is escaped, so it has to grow as it goes
+	body := strings.Repeat(`\"ab\x41\n`, 1000)
+	result, p := runParser(`"`+body+`"`, StringLit(`"`))
+	require.Equal(t, "", p.Error.expected)
+	require.Equal(t, strings.Repeat("\"abA\n", 1000), result.Token)
+}
+
+func TestCustomStringLiteralMaxLength(t *testing.T) {
+	parser := CustomStringLiteral(IsValidRegexpDelimiter, _Escapes, MaxLength(5))
+	t.Run("test short enough", func(t *testing.T) {
+		result, p := runParser(`"héllo"`, parser)
+		require.Equal(t, "", p.Error.expected)
+		require.Equal(t, "héllo", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test escapes count once decoded", func(t *testing.T) {
+		result, p := runParser(`"\u0041\tb\"d"`, parser)
+		require.Equal(t, "A\tb\"d", result.Token)
+		require.Equal(t, ``, p.Get())
+	})
+
+	t.Run("test too long", func(t *testing.T) {
+		_, p := runParser(`"hello world"`, parser)
+		require.Equal(t, "offset 6: expected at most 5 characters", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test too long after an escape", func(t *testing.T) {
+		_, p := runParser(`"hell\to"`, parser)
+		require.Equal(t, "offset 7: expected at most 5 characters", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test escape that goes over", func(t *testing.T) {
+		_, p := runParser(`"hello\n"`, parser)
+		require.Equal(t, "offset 6: expected at most 5 characters", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestRegexpReplaceLiteral(t *testing.T) {
+	parser := RegexpReplaceLiteral('%')
+	t.Run("test fixed delimiter", func(t *testing.T) {
+		result, p := runParser("%a/b%c/d% rest", parser)
+		require.Equal(t, []string{"a/b", "c/d"}, result.Flatten())
+		require.Equal(t, '%', result.Opener)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test escaped delimiter", func(t *testing.T) {
+		result, _ := runParser(`%100\%%all%`, parser)
+		require.Equal(t, []string{"100%", "all"}, result.Flatten())
+	})
+
+	t.Run("test other delimiter", func(t *testing.T) {
+		_, p := runParser("/a/b/", parser)
+		require.Equal(t, "offset 0: expected regexp delimiter", p.Error.Error())
+	})
+}
+
+func TestRegexpLiteralEscapes(t *testing.T) {
+	t.Run("test escapes are left for the regexp engine", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			`/a\/b/`:          `a/b`,
+			`/\d+\n\t\u0041/`: `\d+\n\t\u0041`,
+			`/a\\/`:           `a\\`,
+			`/\x/`:            `\x`,
+			`{a\}b}`:          `a\}b`,
+			`#a\#b#`:          `a#b`,
+			`.a\.b.`:          `a\.b`,
+			`?a\?b?`:          `a\?b`,
+		} {
+			result, p := runParser(input, UnicodeRegexpMatchLiteral())
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Token, input)
+			require.Equal(t, "", p.Get(), input)
+		}
+	})
+
+	t.Run("test escaped metacharacter closer keeps its backslash", func(t *testing.T) {
+		isPipe := func(r rune) (bool, rune) { return r == '|', '|' }
+		result, _ := runParser(`|a\|b|`, CustomRegexpMatchLiteral(isPipe, nil))
+		require.Equal(t, `a\|b`, result.Token)
+		require.True(t, regexp.MustCompile(result.Token).MatchString("a|b"))
+	})
+
+	t.Run("test extra escapes are decoded", func(t *testing.T) {
+		result, _ := runParser(`/a\qb/`, CustomRegexpMatchLiteral(IsValidRegexpDelimiter, map[rune]rune{'q': '"'}))
+		require.Equal(t, `a"b`, result.Token)
+	})
+
+	t.Run("test replace literal", func(t *testing.T) {
+		result, p := runParser(`/\d\//\n\//`, UnicodeRegexpReplaceLiteral())
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Equal(t, `\d/`, result.Child[0].Token)
+		require.Equal(t, "\n/", result.Child[1].Token)
+	})
+}
+
+func TestCompileRegexp(t *testing.T) {
+	t.Run("test match literal", func(t *testing.T) {
+		result, p := runParser(` /a\/\d+/ rest`, UnicodeRegexpMatchLiteral(CompileRegexp("")))
+		require.False(t, p.Errored(), p.Error.Error())
+		re := result.Result.(*regexp.Regexp)
+		require.True(t, re.MatchString("xa/12"))
+		require.Equal(t, `a/\d+`, result.Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test flags", func(t *testing.T) {
+		result, _ := runParser(`/^abc$/`, UnicodeRegexpMatchLiteral(CompileRegexp("im")))
+		require.True(t, result.Result.(*regexp.Regexp).MatchString("x\nABC\ny"))
+		require.Panics(t, func() { CompileRegexp("g") })
+	})
+
+	t.Run("test replace literal", func(t *testing.T) {
+		result, p := runParser(`/(\w+)@/${1} at /`, UnicodeRegexpReplaceLiteral(CompileRegexp("")))
+		require.False(t, p.Errored(), p.Error.Error())
+		re := result.Child[0].Result.(*regexp.Regexp)
+		require.Equal(t, "me at example", re.ReplaceAllString("me@example", result.Child[1].Token))
+	})
+
+	t.Run("test invalid pattern", func(t *testing.T) {
+		_, p := runParser(` /a(b/`, UnicodeRegexpMatchLiteral(CompileRegexp("")))
+		require.Equal(t, "offset 1: expected valid regexp (missing closing ): `a(b`)", p.Error.Error())
+		require.Equal(t, 1, p.Pos)
+
+		_, p = runParser(`{a(b}{c}`, UnicodeRegexpReplaceLiteral(CompileRegexp("")))
+		require.Equal(t, "offset 0: expected valid regexp (missing closing ): `a(b`)", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test not compiled by default", func(t *testing.T) {
+		result, _ := runParser(`/a(b/`, UnicodeRegexpMatchLiteral())
+		require.Nil(t, result.Result)
+		require.Equal(t, "a(b", result.Token)
+	})
+}
+
+func TestRegexpFlags(t *testing.T) {
+	t.Run("test match literal", func(t *testing.T) {
+		parser := UnicodeRegexpMatchLiteral(RegexpFlags("gimsx"))
+		result, p := runParser(` /a+/gi rest`, parser)
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Equal(t, "a+", result.Token)
+		require.Len(t, result.Child, 1)
+		require.Equal(t, "gi", result.Child[0].Token)
+		require.Equal(t, 5, result.Child[0].Start)
+		require.Equal(t, 7, result.Child[0].End)
+		require.Equal(t, 7, result.End)
+		require.Equal(t, " rest", p.Get())
+
+		result, _ = runParser(`/a+/ rest`, parser)
+		require.Equal(t, "", result.Child[0].Token)
+	})
+
+	t.Run("test unknown flags are left alone", func(t *testing.T) {
+		result, p := runParser(`/a+/gq`, UnicodeRegexpMatchLiteral(RegexpFlags("gimsx")))
+		require.Equal(t, "g", result.Child[0].Token)
+		require.Equal(t, "q", p.Get())
+	})
+
+	t.Run("test replace literal", func(t *testing.T) {
+		result, p := runParser(`s/a/b/g;`, Seq("s", UnicodeRegexpReplaceLiteral(RegexpFlags("gi"))))
+		require.False(t, p.Errored(), p.Error.Error())
+		literal := result.Child[1]
+		require.Len(t, literal.Child, 3)
+		require.Equal(t, "g", literal.Child[2].Token)
+		require.Equal(t, ";", p.Get())
+	})
+
+	t.Run("test flags are used when compiling", func(t *testing.T) {
+		result, _ := runParser(`/abc/gi`, UnicodeRegexpMatchLiteral(RegexpFlags("gi"), CompileRegexp("")))
+		require.True(t, result.Result.(*regexp.Regexp).MatchString("ABC"))
+
+		result, _ = runParser(`/a.c/x/is`, UnicodeRegexpReplaceLiteral(RegexpFlags("is"), CompileRegexp("i")))
+		require.True(t, result.Child[0].Result.(*regexp.Regexp).MatchString("A\nC"))
+	})
+
+	t.Run("test no flags by default", func(t *testing.T) {
+		result, p := runParser(`/a+/g`, UnicodeRegexpMatchLiteral())
+		require.Len(t, result.Child, 0)
+		require.Equal(t, "g", p.Get())
+	})
+}
+
+func TestRegexpLiteralDelimiters(t *testing.T) {
+	t.Run("test match literal", func(t *testing.T) {
+		result, _ := runParser("«a+»", UnicodeRegexpMatchLiteral())
+		require.Equal(t, "a+", result.Token)
+		require.Equal(t, '«', result.Opener)
+		require.Equal(t, '»', result.Closer)
+
+		result, _ = runParser("“a+”", UnicodeRegexpMatchLiteral())
+		require.Equal(t, '“', result.Opener)
+		require.Equal(t, '”', result.Closer)
+	})
+
+	t.Run("test replace literal", func(t *testing.T) {
+		result, _ := runParser("{a+}/b/", CustomRegexpReplaceLiteral(IsValidRegexpDelimiter, _Escapes, MixedDelimiters()))
+		require.Equal(t, '{', result.Opener)
+		require.Equal(t, '}', result.Closer)
+		require.Equal(t, '{', result.Child[0].Opener)
+		require.Equal(t, '}', result.Child[0].Closer)
+		require.Equal(t, '/', result.Child[1].Opener)
+		require.Equal(t, '/', result.Child[1].Closer)
+	})
+
+	t.Run("test same delimiters", func(t *testing.T) {
+		result, _ := runParser("#a+#b#", UnicodeRegexpReplaceLiteral())
+		require.Equal(t, '#', result.Child[1].Opener)
+		require.Equal(t, '#', result.Child[1].Closer)
+	})
+}
+
+func TestCustomRegexpReplaceLiteral(t *testing.T) {
+	parser := UnicodeRegexpReplaceLiteral()
+	t.Run("test slashes", func(t *testing.T) {
+		result, p := runParser("/a+/b/ rest", parser)
+		require.Equal(t, "a+", result.Child[0].Token)
+		require.Equal(t, "b", result.Child[1].Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test braces", func(t *testing.T) {
+		result, p := runParser("{a+}{b}", parser)
+		require.Equal(t, "a+", result.Child[0].Token)
+		require.Equal(t, "b", result.Child[1].Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test mixed delimiters", func(t *testing.T) {
+		_, p := runParser("{a+}/b/", parser)
+		require.Equal(t, "offset 4: expected {", p.Error.Error())
+	})
+
+	t.Run("test mixed delimiters allowed", func(t *testing.T) {
+		result, p := runParser("{a+}/b/", CustomRegexpReplaceLiteral(IsValidRegexpDelimiter, _Escapes, MixedDelimiters()))
+		require.Equal(t, "a+", result.Child[0].Token)
+		require.Equal(t, "b", result.Child[1].Token)
+		require.Equal(t, "", p.Get())
+	})
+}
+
+func TestInvalidUTF8Delimiter(t *testing.T) {
+	parsers := map[string]Parser{
+		"string":  UnicodeStringLiteral(),
+		"match":   UnicodeRegexpMatchLiteral(),
+		"replace": UnicodeRegexpReplaceLiteral(),
+	}
+	// every prefix of a multibyte character, plus a stray continuation byte
+	inputs := []string{"\xe2", "\xe2\x80", "\xe2\x80hi\xe2\x80", "\x9d", "\xff"}
+	for name, parser := range parsers {
+		for _, input := range inputs {
+			t.Run(name+" "+strconv.Quote(input), func(t *testing.T) {
+				_, p := runParser(input, parser)
+				require.Equal(t, "offset 0: expected valid UTF-8", p.Error.Error())
+				require.Equal(t, 0, p.Pos)
+			})
+		}
+	}
+
+	t.Run("test second replace delimiter", func(t *testing.T) {
+		_, p := runParser("{a}\xe2\x80", UnicodeRegexpReplaceLiteral())
+		require.Equal(t, "offset 3: expected valid UTF-8", p.Error.Error())
+	})
+}
+
+func TestCustomStringLiteralPercentEscapes(t *testing.T) {
+	parser := CustomStringLiteral(func(r rune) (bool, rune) { return r == '"', '"' }, _Escapes, PercentEscapes())
+	t.Run("test decoding", func(t *testing.T) {
+		result, p := runParser(`"a%41%2fb%E2%82%AC%22" rest`, parser)
+		require.Equal(t, `aA/b€"`, result.Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test backslash is not an escape", func(t *testing.T) {
+		result, p := runParser(`"a\n"`, parser)
+		require.Equal(t, `a\n`, result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test invalid hex", func(t *testing.T) {
+		_, p := runParser(`"a%4g"`, parser)
+		require.Equal(t, "offset 3: expected [a-f0-9]", p.Error.Error())
+	})
+
+	t.Run("test truncated", func(t *testing.T) {
+		_, p := runParser(`"a%4`, parser)
+		require.Equal(t, "offset 3: expected [a-f0-9]{2}", p.Error.Error())
+	})
+}
+
+func TestIsValidRegexpDelimiterWith(t *testing.T) {
+	isValid := IsValidRegexpDelimiterWith(map[rune]rune{'⌈': '⌉', '»': '«', 'q': 'p'})
+	for open, close := range map[rune]rune{'⌈': '⌉', '»': '«', 'q': 'p', '(': ')', '«': '»', '/': '/'} {
+		valid, closer := isValid(open)
+		require.True(t, valid, string(open))
+		require.Equal(t, close, closer, string(open))
+	}
+	valid, _ := isValid('a')
+	require.False(t, valid)
+
+	t.Run("test literals", func(t *testing.T) {
+		result, p := runParser("⌈hello⌉", CustomStringLiteral(isValid, _Escapes))
+		require.Equal(t, "hello", result.Token)
+		require.Equal(t, "", p.Get())
+
+		result, p = runParser("⌈a+⌉⌈b⌉", CustomRegexpReplaceLiteral(isValid, _Escapes))
+		require.Equal(t, []string{"a+", "b"}, result.Flatten())
+		require.Equal(t, "", p.Get())
+	})
+}
+
+func TestIsValidRegexpDelimiterStrict(t *testing.T) {
+	for _, r := range "_-‐—‿" {
+		valid, _ := IsValidRegexpDelimiterStrict(r)
+		require.False(t, valid, string(r))
+
+		valid, _ = IsValidRegexpDelimiter(r)
+		require.True(t, valid, string(r))
+	}
+
+	valid, closer := IsValidRegexpDelimiterStrict('/')
+	require.True(t, valid)
+	require.Equal(t, '/', closer)
+
+	valid, closer = IsValidRegexpDelimiterStrict('{')
+	require.True(t, valid)
+	require.Equal(t, '}', closer)
+
+	valid, _ = IsValidRegexpDelimiterStrict('a')
+	require.False(t, valid)
+}
+
+func TestLiteralsWithoutWhitespace(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		result, p := runParser(`"hello"`, NoAutoWS(StringLit(`"`)))
+		require.Equal(t, "hello", result.Token)
+		require.Equal(t, "", p.Get())
+
+		_, p = runParser(` "hello"`, NoAutoWS(StringLit(`"`)))
+		require.Equal(t, "offset 0: expected \"", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("number", func(t *testing.T) {
+		result, p := runParser(`12`, NoAutoWS(NumberLit()))
+		require.Equal(t, int64(12), result.Result)
+		require.Equal(t, "", p.Get())
+
+		_, p = runParser("\t12", NoAutoWS(NumberLit()))
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("whitespace is skipped by default", func(t *testing.T) {
+		result, p := runParser(` "hello"`, StringLit(`"`))
+		require.Equal(t, "hello", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+}
+
+func TestUnhex(t *testing.T) {
+	tests := map[int64]string{
+		0xF:        "F",
+		0x5:        "5",
+		0xFF:       "FF",
+		0xFFF:      "FFF",
+		0xA4B:      "a4b",
+		0xFFFF:     "FFFF",
+		0xBEEFCAFE: "beeFCAfe",
+	}
+	for expected, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			r, ok := unhex(input)
+			require.True(t, ok)
+			require.EqualValues(t, expected, r)
+		})
+	}
+
+	t.Run("Fails on non hex chars", func(t *testing.T) {
+		_, ok := unhex("hello")
+		require.False(t, ok)
+	})
+}
+
+func TestParseHex(t *testing.T) {
+	r, ok := ParseHex("1f600")
+	require.True(t, ok)
+	require.Equal(t, '😀', r)
+
+	r, ok = ParseHex("7FFFFFFF")
+	require.True(t, ok)
+	require.Equal(t, rune(0x7FFFFFFF), r)
+
+	for _, input := range []string{"", "0x1F", "1g", "123456789", "80000000"} {
+		_, ok := ParseHex(input)
+		require.False(t, ok, input)
+	}
+}
+
+func TestIdentLit(t *testing.T) {
+	parser := IdentLit([]*unicode.RangeTable{unicode.L, unicode.Pc}, []*unicode.RangeTable{unicode.L, unicode.Nd, unicode.Pc})
+	t.Run("test identifiers", func(t *testing.T) {
+		for _, input := range []string{"x", "_private", "camelCase2", "名前", "café_au_lait", "Δx"} {
+			result, p := runParser(" "+input+" = 1", parser)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, 1, result.Start, input)
+			require.Equal(t, 1+len(input), result.End, input)
+			require.Equal(t, " = 1", p.Get(), input)
+		}
+	})
+
+	t.Run("test stops at other characters", func(t *testing.T) {
+		result, p := runParser("foo-bar", parser)
+		require.Equal(t, "foo", result.Token)
+		require.Equal(t, "-bar", p.Get())
+	})
+
+	t.Run("test invalid start", func(t *testing.T) {
+		for _, input := range []string{"1abc", "-x", "", "\xff"} {
+			_, p := runParser(input, parser)
+			require.Equal(t, "offset 0: expected identifier", p.Error.Error(), input)
+		}
+	})
+}
+
+func TestBoolLit(t *testing.T) {
+	parser := BoolLit([]string{"true", "yes", "on"}, []string{"false", "no", "off"})
+	t.Run("test words", func(t *testing.T) {
+		for input, expected := range map[string]bool{
+			"true":  true,
+			"Yes":   true,
+			"ON":    true,
+			"false": false,
+			"no":    false,
+			"Off":   false,
+		} {
+			result, p := runParser(input+", rest", parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, ", rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test word boundary", func(t *testing.T) {
+		for _, input := range []string{"trueish", "no_way", "on1", "offé"} {
+			_, p := runParser(input, parser)
+			require.Equal(t, "offset 0: expected true or yes or on or false or no or off", p.Error.Error(), input)
+		}
+	})
+
+	t.Run("test end of input", func(t *testing.T) {
+		result, p := runParser(" yes", parser)
+		require.Equal(t, true, result.Result)
+		require.Equal(t, 1, result.Start)
+		require.Equal(t, "", p.Get())
+	})
+}
+
+func TestKeywordLit(t *testing.T) {
+	parser := KeywordLit("SELECT")
+	t.Run("test any case", func(t *testing.T) {
+		for _, input := range []string{"SELECT", "select", "Select", "sElEcT"} {
+			result, p := runParser(" "+input+" *", parser)
+			require.Equal(t, "SELECT", result.Token, input)
+			require.Equal(t, 1, result.Start, input)
+			require.Equal(t, 7, result.End, input)
+			require.Equal(t, " *", p.Get(), input)
+		}
+	})
+
+	t.Run("test punctuation", func(t *testing.T) {
+		result, p := runParser("select(", parser)
+		require.Equal(t, "SELECT", result.Token)
+		require.Equal(t, "(", p.Get())
+	})
+
+	t.Run("test longer identifier", func(t *testing.T) {
+		for _, input := range []string{"selected", "select_all", "select1", "sel"} {
+			_, p := runParser(input, parser)
+			require.Equal(t, "offset 0: expected SELECT", p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+}
+
+func TestNullLit(t *testing.T) {
+	parser := NullLit("null")
+	t.Run("test null", func(t *testing.T) {
+		result, p := runParser(" null", parser)
+		require.Nil(t, result.Result)
+		require.Equal(t, "null", result.Token)
+		require.Equal(t, 1, result.Start)
+		require.Equal(t, 5, result.End)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test trailing punctuation", func(t *testing.T) {
+		result, p := runParser("null, ", parser)
+		require.Equal(t, "null", result.Token)
+		require.Equal(t, ", ", p.Get())
+	})
+
+	t.Run("test longer identifier", func(t *testing.T) {
+		_, p := runParser("nullable", parser)
+		require.Equal(t, "offset 0: expected null", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test case", func(t *testing.T) {
+		_, p := runParser("NULL", parser)
+		require.Equal(t, "offset 0: expected null", p.Error.Error())
+	})
+}
+
+func TestNumberLit(t *testing.T) {
+	parser := NumberLit()
+	t.Run("test int", func(t *testing.T) {
+		result, p := runParser("1234", parser)
+		require.Equal(t, int64(1234), result.Result)
+		require.Equal(t, "1234", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test float", func(t *testing.T) {
+		result, p := runParser("12.34", parser)
+		require.Equal(t, 12.34, result.Result)
+		require.Equal(t, "12.34", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test negative float", func(t *testing.T) {
+		result, p := runParser("-12.34", parser)
+		require.Equal(t, -12.34, result.Result)
+		require.Equal(t, "-12.34", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test token keeps source text", func(t *testing.T) {
+		result, p := runParser("+1.0 ", parser)
+		require.Equal(t, 1.0, result.Result)
+		require.Equal(t, "+1.0", result.Token)
+		require.Equal(t, " ", p.Get())
+	})
+
+	t.Run("without leading zero", func(t *testing.T) {
+		result, p := runParser("-.34", parser)
+		require.Equal(t, -.34, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("leading decimal point", func(t *testing.T) {
+		result, p := runParser(".5", parser)
+		require.Equal(t, .5, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("signed leading decimal point", func(t *testing.T) {
+		result, p := runParser("+.5", parser)
+		require.Equal(t, .5, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("trailing decimal point", func(t *testing.T) {
+		result, p := runParser("5.", parser)
+		require.Equal(t, 5.0, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("lone decimal point", func(t *testing.T) {
+		_, p := runParser(".", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("decimal point without digits before exponent", func(t *testing.T) {
+		_, p := runParser(".e5", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("scientific notation", func(t *testing.T) {
+		result, p := runParser("12.34e3", parser)
+		require.Equal(t, 12.34e3, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("scientific notation without decimal", func(t *testing.T) {
+		result, p := runParser("34e3", parser)
+		require.Equal(t, 34e3, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("scientific notation negative power", func(t *testing.T) {
+		result, p := runParser("34e-3", parser)
+		require.Equal(t, 34e-3, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("negative scientific notation negative power", func(t *testing.T) {
+		result, p := runParser("-.34e-3", parser)
+		require.Equal(t, -.34e-3, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("range", func(t *testing.T) {
+		result, p := runParser("1..10", parser)
+		require.Equal(t, int64(1), result.Result)
+		require.Equal(t, "..10", p.Get())
+	})
+
+	t.Run("exponent", func(t *testing.T) {
+		result, p := runParser("1e10", parser)
+		require.Equal(t, 1e10, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("exponent without digits", func(t *testing.T) {
+		_, p := runParser("1e", parser)
+		require.Equal(t, "offset 2: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("positive exponent without digits", func(t *testing.T) {
+		_, p := runParser("1e+", parser)
+		require.Equal(t, "offset 3: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("negative exponent without digits", func(t *testing.T) {
+		_, p := runParser("1e-x", parser)
+		require.Equal(t, "offset 3: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("partial match", func(t *testing.T) {
+		result, p := runParser("-1.34foo", parser)
+		require.Equal(t, -1.34, result.Result)
+		require.Equal(t, "foo", p.Get())
+	})
+
+	t.Run("hex int", func(t *testing.T) {
+		result, p := runParser("0xFF", parser)
+		require.Equal(t, int64(255), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("negative hex int", func(t *testing.T) {
+		result, p := runParser("-0X1a", parser)
+		require.Equal(t, int64(-26), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("octal int", func(t *testing.T) {
+		result, p := runParser("0o17", parser)
+		require.Equal(t, int64(15), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("binary int", func(t *testing.T) {
+		result, p := runParser("+0b1010", parser)
+		require.Equal(t, int64(10), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("prefixed partial match", func(t *testing.T) {
+		result, p := runParser("0b101foo", parser)
+		require.Equal(t, int64(5), result.Result)
+		require.Equal(t, "foo", p.Get())
+	})
+
+	t.Run("invalid binary digit", func(t *testing.T) {
+		_, p := runParser("0b1021", parser)
+		require.Equal(t, "offset 4: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("invalid octal digit", func(t *testing.T) {
+		_, p := runParser("0o78", parser)
+		require.Equal(t, "offset 3: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("prefix without digits", func(t *testing.T) {
+		_, p := runParser("0x", parser)
+		require.Equal(t, "offset 2: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("hex float", func(t *testing.T) {
+		result, p := runParser("0x1.8p3", parser)
+		require.Equal(t, 12.0, result.Result)
+		require.Equal(t, "0x1.8p3", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("negative hex float without fraction", func(t *testing.T) {
+		result, p := runParser("-0X1_0P-2", parser)
+		require.Equal(t, -4.0, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("hex float with leading point", func(t *testing.T) {
+		result, p := runParser("0x.8p1", parser)
+		require.Equal(t, 1.0, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("hex float without exponent", func(t *testing.T) {
+		_, p := runParser("0x1.8", parser)
+		require.Equal(t, "offset 5: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("hex float without exponent digits", func(t *testing.T) {
+		_, p := runParser("0x1.8p+", parser)
+		require.Equal(t, "offset 7: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("digit separators", func(t *testing.T) {
+		result, p := runParser("1_000_000", parser)
+		require.Equal(t, int64(1000000), result.Result)
+		require.Equal(t, 0, result.Start)
+		require.Equal(t, 9, result.End)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("float digit separators", func(t *testing.T) {
+		result, p := runParser("3.141_592e1_0", parser)
+		require.Equal(t, 3.141592e10, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("hex digit separators", func(t *testing.T) {
+		result, p := runParser("0xFF_FF", parser)
+		require.Equal(t, int64(0xFFFF), result.Result)
+		require.Equal(t, "0xFF_FF", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("leading separator", func(t *testing.T) {
+		_, p := runParser("_1", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("trailing separator", func(t *testing.T) {
+		_, p := runParser("1_", parser)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("doubled separator", func(t *testing.T) {
+		_, p := runParser("1__2", parser)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("separator before decimal point", func(t *testing.T) {
+		_, p := runParser("1_.5", parser)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("non matching string", func(t *testing.T) {
+		_, p := runParser("foo", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		_, p := runParser("-.", parser)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("bare sign", func(t *testing.T) {
+		_, p := runParser("-", parser)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("sign without digits", func(t *testing.T) {
+		_, p := runParser("+foo", parser)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestSign(t *testing.T) {
+	t.Run("test signs", func(t *testing.T) {
+		for input, sign := range map[string]int64{" -x": -1, " +x": 1, " x": 1} {
+			result, p := runParser(input, Sign())
+			require.False(t, p.Errored())
+			require.Equal(t, sign, result.Result, input)
+			require.Equal(t, "x", p.Get())
+		}
+	})
+
+	t.Run("test numeric parsers share sign handling", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			parser Parser
+			plus   interface{}
+			minus  interface{}
+		}{
+			{"number", NumberLit(), int64(12), int64(-12)},
+			{"int", IntLit(), int64(12), int64(-12)},
+			{"float", FloatLit(), float64(12), float64(-12)},
+			{"big number", BigNumberLit(), big.NewInt(12), big.NewInt(-12)},
+			{"decimal", DecimalLit(), Decimal{Coefficient: 12}, Decimal{Coefficient: -12}},
+			{"localized number", LocalizedNumberLit(',', '.'), int64(12), int64(-12)},
+			{"radix number", RadixNumberLit(), int64(12), int64(-12)},
+			{"duration", DurationLit(), 12 * time.Second, -12 * time.Second},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				suffix := ""
+				if test.name == "duration" {
+					suffix = "s"
+				}
+				result, p := runParser("+12"+suffix, test.parser)
+				require.False(t, p.Errored(), p.Error.Error())
+				require.Equal(t, test.plus, result.Result)
+
+				result, p = runParser("-12"+suffix, test.parser)
+				require.False(t, p.Errored(), p.Error.Error())
+				require.Equal(t, test.minus, result.Result)
+
+				_, p = runParser("--12"+suffix, test.parser)
+				require.True(t, p.Errored())
+				require.Equal(t, 0, p.Pos)
+			})
+		}
+	})
+
+	t.Run("test exponent signs", func(t *testing.T) {
+		result, _ := runParser("1e+2", NumberLit())
+		require.Equal(t, float64(100), result.Result)
+		result, _ = runParser("1e-2", StrictNumberLit())
+		require.Equal(t, 0.01, result.Result)
+	})
+}
+
+func TestLimitedNumberLit(t *testing.T) {
+	parser := LimitedNumberLit(4)
+	t.Run("test within limit", func(t *testing.T) {
+		for input, expected := range map[string]interface{}{
+			"1234":     int64(1234),
+			"-12.34":   -12.34,
+			"1_234e10": 1234e10,
+			"0xBEEF":   int64(0xBEEF),
+			"0x1.ep12": 7680.0,
+		} {
+			result, p := runParser(input, parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, "", p.Get(), input)
+		}
+	})
+
+	t.Run("test too many digits", func(t *testing.T) {
+		_, p := runParser("12345", parser)
+		require.Equal(t, "offset 4: expected at most 4 digits", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test too many fraction digits", func(t *testing.T) {
+		_, p := runParser("-12.3_45", parser)
+		require.Equal(t, "offset 7: expected at most 4 digits", p.Error.Error())
+	})
+
+	t.Run("test too many hex digits", func(t *testing.T) {
+		_, p := runParser("0xDEADBEEF", parser)
+		require.Equal(t, "offset 6: expected at most 4 digits", p.Error.Error())
+	})
+
+	t.Run("test unlimited", func(t *testing.T) {
+		input := "1." + strings.Repeat("0", 1000)
+		result, p := runParser(input, LimitedNumberLit(0))
+		require.Equal(t, 1.0, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test with other options", func(t *testing.T) {
+		parser := NumberLit(MaxDigits(4), NoPlusSign(), NoTrailingDot())
+		result, p := runParser("1234.foo", parser)
+		require.Equal(t, int64(1234), result.Result)
+		require.Equal(t, ".foo", p.Get())
+
+		_, p = runParser("+12", parser)
+		require.Equal(t, "offset 0: expected number without + sign", p.Error.Error())
+
+		_, p = runParser("12345.foo", parser)
+		require.Equal(t, "offset 4: expected at most 4 digits", p.Error.Error())
+	})
+}
+
+func TestNumberLitNoPlusSign(t *testing.T) {
+	parser := NumberLit(NoPlusSign())
+	t.Run("test plus is an error", func(t *testing.T) {
+		_, p := runParser("+12", parser)
+		require.Equal(t, "offset 0: expected number without + sign", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test minus and no sign are allowed", func(t *testing.T) {
+		result, _ := runParser("-12", parser)
+		require.Equal(t, int64(-12), result.Result)
+		result, _ = runParser("1.5e+3", parser)
+		require.Equal(t, 1500.0, result.Result)
+	})
+
+	t.Run("test plus is allowed by default", func(t *testing.T) {
+		result, _ := runParser("+12", NumberLit())
+		require.Equal(t, int64(12), result.Result)
+	})
+}
+
+func TestComplexLit(t *testing.T) {
+	parser := ComplexLit()
+	t.Run("test forms", func(t *testing.T) {
+		for input, expected := range map[string]complex128{
+			"3":       complex(3, 0),
+			"-2.5":    complex(-2.5, 0),
+			"2i":      complex(0, 2),
+			"i":       complex(0, 1),
+			"-i":      complex(0, -1),
+			"3+4i":    complex(3, 4),
+			"3-4i":    complex(3, -4),
+			"1.5-i":   complex(1.5, -1),
+			"-1e2+i":  complex(-100, 1),
+			"0x10+2i": complex(16, 2),
+		} {
+			result, p := runParser(" "+input+" rest", parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, 1, result.Start, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test real part only", func(t *testing.T) {
+		for input, rest := range map[string]string{
+			"3+4":   "+4",
+			"3 +4i": " +4i",
+			"3+4if": "+4if",
+			"3+x":   "+x",
+			"2in":   "in",
+		} {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, rest, p.Get(), input)
+			require.Equal(t, complex(float64(input[0]-'0'), 0), result.Result, input)
+			require.Equal(t, input[:1], result.Token, input)
+		}
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"x":     "offset 0: expected complex number",
+			"if":    "offset 0: expected complex number",
+			"+":     "offset 1: expected number",
+			"1__2i": "offset 1: expected number",
+		} {
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+}
+
+func TestTimeLit(t *testing.T) {
+	t.Run("test rfc3339", func(t *testing.T) {
+		parser := TimeLit(time.RFC3339)
+		result, p := runParser(" 2021-03-04T05:06:07+08:00 rest", parser)
+		require.False(t, p.Errored(), p.Error.Error())
+		expected := time.Date(2021, 3, 4, 5, 6, 7, 0, time.FixedZone("", 8*60*60))
+		require.True(t, expected.Equal(result.Result.(time.Time)))
+		require.Equal(t, "2021-03-04T05:06:07+08:00", result.Token)
+		require.Equal(t, 1, result.Start)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("test trailing punctuation", func(t *testing.T) {
+		result, p := runParser(`[2021-03-04, 2022-01-02]`, Seq("[", Some(TimeLit("2006-01-02"), ","), "]"))
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Len(t, result.Child[1].Child, 2)
+		require.Equal(t, "2022-01-02", result.Child[1].Child[1].Token)
+	})
+
+	t.Run("test layouts with spaces", func(t *testing.T) {
+		result, p := runParser("Mon, 02 Jan 2006 15:04:05 MST;", TimeLit(time.RFC1123))
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Equal(t, "Mon, 02 Jan 2006 15:04:05 MST", result.Token)
+		require.Equal(t, ";", p.Get())
+	})
+
+	t.Run("test invalid", func(t *testing.T) {
+		for _, input := range []string{"2021-13-01", "2021-03-04T05", "yesterday", ""} {
+			_, p := runParser(" "+input, TimeLit("2006-01-02"))
+			require.Equal(t, "offset 1: expected time like 2006-01-02", p.Error.Error(), input)
+		}
+	})
+}
+
+func TestNumberLitNoTrailingDot(t *testing.T) {
+	parser := NumberLit(NoTrailingDot())
+	t.Run("test dot without digits is left", func(t *testing.T) {
+		for input, rest := range map[string]string{
+			"1.method": ".method",
+			"1.":       ".",
+			"-12.e5":   ".e5",
+			"0x1f.x":   ".x",
+		} {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored(), input)
+			require.IsType(t, int64(0), result.Result, input)
+			require.Equal(t, rest, p.Get(), input)
+		}
+	})
+
+	t.Run("test decimal points still work", func(t *testing.T) {
+		for input, expected := range map[string]float64{
+			"1.5":     1.5,
+			".5":      0.5,
+			"1.0e2":   100,
+			"0x1.8p1": 3,
+		} {
+			result, p := runParser(input+".x", parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, ".x", p.Get(), input)
+		}
+	})
+
+	t.Run("test member access", func(t *testing.T) {
+		result, p := runParser("obj.1.method", Seq("obj", ".", parser, ".", "method"))
+		require.False(t, p.Errored(), p.Error.Error())
+		require.Equal(t, int64(1), result.Child[2].Result)
+	})
+
+	t.Run("test trailing dot is a float by default", func(t *testing.T) {
+		result, p := runParser("1.", NumberLit())
+		require.Equal(t, 1.0, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+}
+
+func TestMeasureLit(t *testing.T) {
+	parser := MeasureLit([]string{"px", "em", "rem", "%"})
+	t.Run("test measures", func(t *testing.T) {
+		for input, expected := range map[string]Measure{
+			"10px":  {10, "px"},
+			"1.5em": {1.5, "em"},
+			"2rem":  {2, "rem"},
+			"-50%":  {-50, "%"},
+		} {
+			result, p := runParser(" "+input+" rest", parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, expected.Unit, result.Token, input)
+			require.Equal(t, input[:len(input)-len(expected.Unit)], result.Child[0].Token, input)
+			require.Equal(t, 1, result.Start, input)
+			require.Equal(t, len(input)+1, result.End, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test number is kept as parsed", func(t *testing.T) {
+		result, _ := runParser("10px", parser)
+		require.Equal(t, int64(10), result.Child[0].Result)
+		require.Equal(t, "10px", result.Result.(Measure).String())
+
+		result, _ = runParser("1e2em", parser)
+		require.Equal(t, Measure{100, "em"}, result.Result)
+	})
+
+	t.Run("test bad units", func(t *testing.T) {
+		for _, input := range []string{"10", "10 px", "10pt", "10pxx", "1emx"} {
+			_, p := runParser(input, parser)
+			require.True(t, p.Errored(), input)
+			require.Contains(t, p.Error.Error(), "expected px or em or rem or %", input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+
+		_, p := runParser("px", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+	})
+}
+
+func TestSINumberLit(t *testing.T) {
+	parser := SINumberLit()
+	t.Run("test prefixes", func(t *testing.T) {
+		for input, expected := range map[string]float64{
+			"1k":    1000,
+			"2.5M":  2.5e6,
+			"-3G":   -3e9,
+			"1E":    1e18,
+			"1e3k":  1e6,
+			"5m":    0.005,
+			"10µ":   10e-6,
+			"10μ":   10e-6,
+			"10u":   10e-6,
+			"33n":   33e-9,
+			"42":    42,
+			"0x10k": 16000,
+		} {
+			result, p := runParser(" "+input+" rest", parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, 1, result.Start, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test unknown letters are left", func(t *testing.T) {
+		for input, rest := range map[string]string{
+			"1km": "km",
+			"1x":  "x",
+			"1K":  "K",
+			"1k2": "k2",
+		} {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, 1.0, result.Result, input)
+			require.Equal(t, rest, p.Get(), input)
+		}
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		_, p := runParser("k", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+	})
+}
+
+func TestVersionLit(t *testing.T) {
+	parser := VersionLit()
+	t.Run("test versions", func(t *testing.T) {
+		for input, expected := range map[string][]int{
+			"1":               {1},
+			"1.2.3":           {1, 2, 3},
+			"10.0.20":         {10, 0, 20},
+			"1.0.0-rc.1":      {1, 0, 0},
+			"2.1-alpha-2.x.7": {2, 1},
+		} {
+			result, p := runParser(" "+input+" rest", parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, 1, result.Start, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test things after the version are left", func(t *testing.T) {
+		for input, rest := range map[string]string{
+			"1.2-":        "-",
+			"1.2- x":      "- x",
+			"1.2-rc.":     ".",
+			"1.2-rc..1":   "..1",
+			"1.2-rc_1":    "_1",
+			"1.2.3,1.2.4": ",1.2.4",
+			"1_0.2":       "_0.2",
+		} {
+			_, p := runParser(input, parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, rest, p.Get(), input)
+		}
+	})
+
+	t.Run("test invalid", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"1..2": "offset 2: expected number",
+			"1.2.": "offset 4: expected number",
+			"v1":   "offset 0: expected number",
+			".1":   "offset 0: expected number",
+			"1._2": "offset 2: expected number",
+		} {
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+}
+
+func TestStrictNumberLit(t *testing.T) {
+	parser := StrictNumberLit()
+	t.Run("test valid", func(t *testing.T) {
+		for input, expected := range map[string]interface{}{
+			"0":        int64(0),
+			"-0":       int64(0),
+			"10":       int64(10),
+			"-123":     int64(-123),
+			"0.5":      0.5,
+			"-0.25e+2": -25.0,
+			"1E-2":     0.01,
+			"12e3":     12e3,
+		} {
+			result, p := runParser(input+",", parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, ",", p.Get(), input)
+		}
+	})
+
+	t.Run("test invalid", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"01":   "offset 1: expected number without leading zeros",
+			"-007": "offset 2: expected number without leading zeros",
+			"+1":   "offset 0: expected number",
+			".5":   "offset 0: expected number",
+			"5.":   "offset 2: expected number",
+			"5.e3": "offset 2: expected number",
+			"1e":   "offset 2: expected number",
+			"1e+":  "offset 3: expected number",
+			"-":    "offset 1: expected number",
+		} {
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+
+	t.Run("test stops before extensions", func(t *testing.T) {
+		for _, input := range []string{"1_000", "0x10"} {
+			result, p := runParser(input, parser)
+			require.Equal(t, input[:1], result.Token, input)
+			require.Equal(t, input[1:], p.Get(), input)
+		}
+	})
+}
+
+func TestJSONNumberLit(t *testing.T) {
+	parser := JSONNumberLit()
+	t.Run("test keeps the text", func(t *testing.T) {
+		for _, input := range []string{"0", "-0", "12345678901234567890123", "0.10", "-1.5E+300", "1e999"} {
+			result, p := runParser(" "+input+"]", parser)
+			require.False(t, p.Errored(), input)
+			require.Equal(t, json.Number(input), result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, 1, result.Start, input)
+			require.Equal(t, "]", p.Get(), input)
+		}
+	})
+
+	t.Run("test invalid", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"012": "offset 1: expected number without leading zeros",
+			".5":  "offset 0: expected number",
+			"1.":  "offset 2: expected number",
+			"1e":  "offset 2: expected number",
+			"1E-": "offset 3: expected number",
+		} {
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+}
+
+func TestNumberLitRange(t *testing.T) {
+	parser := NumberLitRange(-1, 100)
+	t.Run("test in range", func(t *testing.T) {
+		for input, expected := range map[string]interface{}{
+			"0":     int64(0),
+			"100":   int64(100),
+			"-1":    int64(-1),
+			"99.5":  99.5,
+			"1e2":   100.0,
+			"0x3F":  int64(63),
+			"-0.25": -0.25,
+		} {
+			result, p := runParser(input, parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, "", p.Get(), input)
+		}
+	})
+
+	t.Run("test out of range", func(t *testing.T) {
+		for _, input := range []string{"101", "100.01", "-2", "1e3", "0xFF"} {
+			_, p := runParser(" "+input, parser)
+			require.Equal(t, "offset 1: expected number from -1 to 100", p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+
+	t.Run("test exact integer bounds", func(t *testing.T) {
+		parser := NumberLitRange(0, 1<<53)
+		_, p := runParser("9007199254740993", parser)
+		require.Equal(t, "offset 0: expected number from 0 to 9.007199254740992e+15", p.Error.Error())
+	})
+}
+
+func TestIntLit(t *testing.T) {
+	parser := IntLit()
+	t.Run("test int", func(t *testing.T) {
+		result, p := runParser("-1_234", parser)
+		require.Equal(t, int64(-1234), result.Result)
+		require.Equal(t, "-1_234", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test hex int", func(t *testing.T) {
+		result, p := runParser("0xbeef", parser)
+		require.Equal(t, int64(0xbeef), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test decimal point", func(t *testing.T) {
+		_, p := runParser("12.5", parser)
+		require.Equal(t, "offset 2: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test exponent", func(t *testing.T) {
+		_, p := runParser("12e5", parser)
+		require.Equal(t, "offset 2: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test hex float", func(t *testing.T) {
+		_, p := runParser("0xe1p3", parser)
+		require.Equal(t, "offset 4: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test overflow", func(t *testing.T) {
+		_, p := runParser("99999999999999999999", parser)
+		require.Equal(t, "offset 0: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("non matching string", func(t *testing.T) {
+		_, p := runParser("foo", parser)
+		require.Equal(t, "offset 0: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestFloatLit(t *testing.T) {
+	parser := FloatLit()
+	t.Run("test int", func(t *testing.T) {
+		result, p := runParser("42", parser)
+		require.Equal(t, float64(42), result.Result)
+		require.Equal(t, "42", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test float", func(t *testing.T) {
+		result, p := runParser("-1.5e2", parser)
+		require.Equal(t, -150.0, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test hex int", func(t *testing.T) {
+		result, p := runParser("0x10", parser)
+		require.Equal(t, float64(16), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test hex float", func(t *testing.T) {
+		result, p := runParser("0x1p-1", parser)
+		require.Equal(t, .5, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("non matching string", func(t *testing.T) {
+		_, p := runParser("foo", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestRadixNumberLit(t *testing.T) {
+	parser := RadixNumberLit()
+	t.Run("test radix numbers", func(t *testing.T) {
+		for input, expected := range map[string]int64{
+			"16rFF":   255,
+			"16r1f":   31,
+			"2r1010":  10,
+			"36#Z":    35,
+			"8#777":   511,
+			"-16r10":  -16,
+			"+16r10":  16,
+			"+5":      5,
+			"42":      42,
+			"-7":      -7,
+			"10r0099": 99,
+		} {
+			result, p := runParser(input+" rest", parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test invalid", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"1r0":                "offset 0: expected base from 2 to 36",
+			"37r0":               "offset 0: expected base from 2 to 36",
+			"-0r0":               "offset 1: expected base from 2 to 36",
+			"2r102":              "offset 4: expected base 2 digit",
+			"16r":                "offset 3: expected base 16 digit",
+			"16r 1":              "offset 3: expected base 16 digit",
+			"r10":                "offset 0: expected number",
+			"36rZZZZZZZZZZZZZZZ": "offset 0: expected number",
+		} {
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+}
+
+func TestScientificLit(t *testing.T) {
+	parser := ScientificLit()
+	t.Run("test exponent", func(t *testing.T) {
+		result, p := runParser("6.022e23 mol", parser)
+		require.Equal(t, 6.022e23, result.Result)
+		require.Equal(t, "6.022e23", result.Token)
+		require.Equal(t, " mol", p.Get())
+	})
+
+	t.Run("test signed exponent", func(t *testing.T) {
+		result, p := runParser("-1E-3", parser)
+		require.Equal(t, -1e-3, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test missing exponent", func(t *testing.T) {
+		_, p := runParser("6.022 mol", parser)
+		require.Equal(t, "offset 5: expected exponent", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test integer", func(t *testing.T) {
+		_, p := runParser("42", parser)
+		require.Equal(t, "offset 2: expected exponent", p.Error.Error())
+	})
+
+	t.Run("test hex", func(t *testing.T) {
+		_, p := runParser("0x1e", parser)
+		require.Equal(t, "offset 4: expected exponent", p.Error.Error())
+	})
+
+	t.Run("test empty exponent", func(t *testing.T) {
+		_, p := runParser("1e", parser)
+		require.Equal(t, "offset 2: expected number", p.Error.Error())
+	})
+}
+
+func TestSpecialFloatLit(t *testing.T) {
+	parser := SpecialFloatLit()
+	t.Run("test nan", func(t *testing.T) {
+		result, p := runParser("NaN", parser)
+		require.True(t, math.IsNaN(result.Result.(float64)))
+		require.Equal(t, "NaN", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test inf", func(t *testing.T) {
+		result, p := runParser("+inf", parser)
+		require.Equal(t, math.Inf(1), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test negative infinity", func(t *testing.T) {
+		result, p := runParser("-Infinity,", parser)
+		require.Equal(t, math.Inf(-1), result.Result)
+		require.Equal(t, "-Infinity", result.Token)
+		require.Equal(t, ",", p.Get())
+	})
+
+	t.Run("test regular float", func(t *testing.T) {
+		result, p := runParser("-12", parser)
+		require.Equal(t, -12.0, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test identifier", func(t *testing.T) {
+		_, p := runParser("information", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test restricted names", func(t *testing.T) {
+		parser := SpecialFloatLit("Inf")
+		result, p := runParser("-inf", parser)
+		require.Equal(t, math.Inf(-1), result.Result)
+		require.Equal(t, "", p.Get())
+
+		_, p = runParser("NaN", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+	})
+
+	require.Panics(t, func() {
+		SpecialFloatLit("Huge")
+	})
+}
+
+func TestSuffixedNumberLit(t *testing.T) {
+	parser := SuffixedNumberLit("L", "f", "u", "u8")
+	t.Run("test int suffix", func(t *testing.T) {
+		result, p := runParser("100L", parser)
+		require.Equal(t, int64(100), result.Result)
+		require.Equal(t, "100", result.Token)
+		require.Equal(t, []Result{{Token: "L", Start: 3, End: 4}}, result.Child)
+		require.Equal(t, 4, result.End)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test float suffix", func(t *testing.T) {
+		result, p := runParser("3.5f+1", parser)
+		require.Equal(t, 3.5, result.Result)
+		require.Equal(t, "f", result.Child[0].Token)
+		require.Equal(t, "+1", p.Get())
+	})
+
+	t.Run("test longest suffix", func(t *testing.T) {
+		result, p := runParser("0xFFu8", parser)
+		require.Equal(t, int64(255), result.Result)
+		require.Equal(t, "u8", result.Child[0].Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test no suffix", func(t *testing.T) {
+		result, p := runParser("42 u", parser)
+		require.Equal(t, int64(42), result.Result)
+		require.Nil(t, result.Child)
+		require.Equal(t, " u", p.Get())
+	})
+
+	t.Run("test unknown suffix", func(t *testing.T) {
+		result, p := runParser("42units", parser)
+		require.Equal(t, int64(42), result.Result)
+		require.Nil(t, result.Child)
+		require.Equal(t, "units", p.Get())
+	})
+}
+
+func TestPercentLit(t *testing.T) {
+	parser := PercentLit()
+	t.Run("test percentages", func(t *testing.T) {
+		for input, expected := range map[string]float64{
+			"50%":   0.5,
+			"12.5%": 0.125,
+			"-200%": -2,
+			"0%":    0,
+		} {
+			result, p := runParser(input+" rest", parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test missing percent", func(t *testing.T) {
+		_, p := runParser(" 50", parser)
+		require.Equal(t, "offset 3: expected %", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test whitespace before percent", func(t *testing.T) {
+		_, p := runParser("50 %", parser)
+		require.Equal(t, "offset 2: expected %", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestDurationLit(t *testing.T) {
+	parser := DurationLit()
+	t.Run("test durations", func(t *testing.T) {
+		for input, expected := range map[string]time.Duration{
+			"1h30m15s":                 time.Hour + 30*time.Minute + 15*time.Second,
+			"1.5s":                     1500 * time.Millisecond,
+			"-250ms":                   -250 * time.Millisecond,
+			"+3us":                     3 * time.Microsecond,
+			"3µs":                      3 * time.Microsecond,
+			"10ns":                     10,
+			"2562047h47m16.854775807s": math.MaxInt64,
+			"0":                        0,
+			"-0":                       0,
+			"+0":                       0,
+		} {
+			result, p := runParser(input+" rest", parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test missing unit", func(t *testing.T) {
+		_, p := runParser("1h30", parser)
+		require.Equal(t, "offset 4: expected duration unit", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test invalid unit", func(t *testing.T) {
+		_, p := runParser("1h3mo", parser)
+		require.Equal(t, "offset 3: expected duration unit", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test empty", func(t *testing.T) {
+		_, p := runParser(" ", parser)
+		require.Equal(t, "offset 1: expected duration", p.Error.Error())
+
+		_, p = runParser("-h", parser)
+		require.Equal(t, "offset 1: expected duration", p.Error.Error())
+	})
+
+	t.Run("test overflow", func(t *testing.T) {
+		_, p := runParser("2562047h48m", parser)
+		require.Equal(t, "offset 8: expected duration up to 2562047h47m16.854775807s", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		_, p = runParser("9999999999h", parser)
+		require.Equal(t, "offset 0: expected duration up to 2562047h47m16.854775807s", p.Error.Error())
+
+		_, p = runParser(strings.Repeat("9", 30)+".5s", parser)
+		require.Equal(t, "offset 0: expected duration up to 2562047h47m16.854775807s", p.Error.Error())
+	})
+
+	t.Run("test only plain digits", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"1e3s":  "offset 1: expected duration unit",
+			"1_0s":  "offset 1: expected duration unit",
+			"0x10s": "offset 1: expected duration unit",
+			"00":    "offset 2: expected duration unit",
+			"0h0":   "offset 3: expected duration unit",
+			".s":    "offset 0: expected duration",
+		} {
+			_, err := time.ParseDuration(input)
+			require.Error(t, err, input)
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+
+		for input, expected := range map[string]time.Duration{".5s": 500 * time.Millisecond, "5.s": 5 * time.Second} {
+			result, p := runParser(input, parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, "", p.Get(), input)
+		}
+	})
+}
+
+func TestLocalizedNumberLit(t *testing.T) {
+	us := LocalizedNumberLit(',', '.')
+	eu := LocalizedNumberLit('.', ',')
+	t.Run("test grouped int", func(t *testing.T) {
+		result, p := runParser("1,234,567", us)
+		require.Equal(t, int64(1234567), result.Result)
+		require.Equal(t, "1,234,567", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test ungrouped int", func(t *testing.T) {
+		result, p := runParser("-1234567", us)
+		require.Equal(t, int64(-1234567), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test us float", func(t *testing.T) {
+		result, p := runParser("1,234.56", us)
+		require.Equal(t, 1234.56, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test eu float", func(t *testing.T) {
+		result, p := runParser("-1.234,56", eu)
+		require.Equal(t, -1234.56, result.Result)
+		require.Equal(t, "-1.234,56", result.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test unicode separators", func(t *testing.T) {
+		result, p := runParser("1’234’567.5", LocalizedNumberLit('’', '.'))
+		require.Equal(t, 1234567.5, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test separator not followed by a digit", func(t *testing.T) {
+		result, p := runParser("1, 2.", us)
+		require.Equal(t, int64(1), result.Result)
+		require.Equal(t, ", 2.", p.Get())
+	})
+
+	t.Run("test short group", func(t *testing.T) {
+		_, p := runParser("1,23", us)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test long group", func(t *testing.T) {
+		_, p := runParser("1,2345", us)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test long first group", func(t *testing.T) {
+		_, p := runParser("1234,567", us)
+		require.Equal(t, "offset 4: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test no digits", func(t *testing.T) {
+		_, p := runParser("-,123", us)
+		require.Equal(t, "offset 1: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	require.Panics(t, func() {
+		LocalizedNumberLit('.', '.')
+	})
+}
+
+func TestRangeLit(t *testing.T) {
+	parser := RangeLit()
+	t.Run("test range", func(t *testing.T) {
+		result, p := runParser("1..10", parser)
+		require.Equal(t, int64(1), result.Child[0].Result)
+		require.Equal(t, int64(10), result.Child[1].Result)
+		require.Equal(t, "..", result.Token)
+		require.Equal(t, 0, result.Start)
+		require.Equal(t, 5, result.End)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test three dots", func(t *testing.T) {
+		result, p := runParser(" -5 ... 2)", parser)
+		require.Equal(t, int64(-5), result.Child[0].Result)
+		require.Equal(t, int64(2), result.Child[1].Result)
+		require.Equal(t, "...", result.Token)
+		require.Equal(t, ")", p.Get())
+	})
+
+	t.Run("test custom separator", func(t *testing.T) {
+		result, p := runParser("1-5", RangeLit("-", ":"))
+		require.Equal(t, int64(1), result.Child[0].Result)
+		require.Equal(t, int64(5), result.Child[1].Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test missing separator", func(t *testing.T) {
+		_, p := runParser("1 10", parser)
+		require.Equal(t, "offset 2: expected .. or ...", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test missing end", func(t *testing.T) {
+		_, p := runParser("1..", parser)
+		require.Equal(t, "offset 3: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("test fractional endpoints", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"1.5..2":   "offset 1: expected integer",
+			"1..2.5":   "offset 4: expected integer",
+			"1e3...10": "offset 1: expected integer",
+		} {
+			_, p := runParser(input, parser)
+			require.Equal(t, expected, p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+}
+
+func TestDecimalLit(t *testing.T) {
+	parser := DecimalLit()
+	t.Run("test decimals", func(t *testing.T) {
+		for input, expected := range map[string]Decimal{
+			"3.14":                  {314, 2},
+			"-0.05":                 {-5, 2},
+			"42":                    {42, 0},
+			"1_000.50":              {100050, 2},
+			"1.5e3":                 {1500, 0},
+			"15e-4":                 {15, 4},
+			".5":                    {5, 1},
+			"5.":                    {5, 0},
+			"9223372036854775807":   {math.MaxInt64, 0},
+			"-922337203685477580.8": {math.MinInt64, 1},
+		} {
+			result, p := runParser(input+" rest", parser)
+			require.Equal(t, expected, result.Result, input)
+			require.Equal(t, input, result.Token, input)
+			require.Equal(t, " rest", p.Get(), input)
+		}
+	})
+
+	t.Run("test overflow", func(t *testing.T) {
+		for _, input := range []string{"9223372036854775808", "1.00000000000000000000", "1e19", "1e99999999999"} {
+			_, p := runParser(input, parser)
+			require.Equal(t, "offset 0: expected number", p.Error.Error(), input)
+			require.Equal(t, 0, p.Pos, input)
+		}
+	})
+
+	t.Run("test zero with a huge exponent", func(t *testing.T) {
+		for _, input := range []string{"0e2000000000", "0.00e2000000000", "-0e2000000000"} {
+			result, p := runParser(input, parser)
+			require.Equal(t, Decimal{0, 0}, result.Result, input)
+			require.Equal(t, "", p.Get(), input)
+		}
+	})
+
+	t.Run("test hex", func(t *testing.T) {
+		_, p := runParser("0x10", parser)
+		require.Equal(t, "offset 0: expected decimal number", p.Error.Error())
+	})
+
+	t.Run("test string", func(t *testing.T) {
+		for expected, d := range map[string]Decimal{
+			"3.14":   {314, 2},
+			"-0.05":  {-5, 2},
+			"0.0005": {5, 4},
+			"42":     {42, 0},
+			"-1.0":   {-10, 1},
+		} {
+			require.Equal(t, expected, d.String())
+		}
+	})
+}
+
+func TestBigNumberLit(t *testing.T) {
+	parser := BigNumberLit()
+	t.Run("test int", func(t *testing.T) {
+		result, p := runParser("1234", parser)
+		require.Equal(t, big.NewInt(1234), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test int larger than int64", func(t *testing.T) {
+		result, p := runParser("-123456789012345678901234567890", parser)
+		expected, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+		require.Equal(t, expected.String(), result.Result.(*big.Int).String())
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test hex int", func(t *testing.T) {
+		result, p := runParser("0xFFFF_FFFF_FFFF_FFFF_FF", parser)
+		expected, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFF", 16)
+		require.Equal(t, expected.String(), result.Result.(*big.Int).String())
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test float", func(t *testing.T) {
+		result, p := runParser("12.5e3foo", parser)
+		f, _ := result.Result.(*big.Float).Float64()
+		require.Equal(t, 12.5e3, f)
+		require.Equal(t, "foo", p.Get())
+	})
+
+	t.Run("test hex float", func(t *testing.T) {
+		result, p := runParser("0x1.8p3", parser)
+		f, _ := result.Result.(*big.Float).Float64()
+		require.Equal(t, 12.0, f)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("test float beyond float64 range", func(t *testing.T) {
+		result, p := runParser("1e400", parser)
+		require.Equal(t, "1e+400", result.Result.(*big.Float).Text('g', 10))
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("non matching string", func(t *testing.T) {
+		_, p := runParser("foo", parser)
 		require.Equal(t, "offset 0: expected number", p.Error.Error())
 		require.Equal(t, 0, p.Pos)
 	})
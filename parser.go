@@ -85,6 +85,7 @@ func Run(parser Parserish, input string, ws ...VoidParser) (result interface{},
 	ps.WS(ps)
 
 	if ps.Error.expected != "" {
+		unterminatedComment(ps)
 		return ret.Result, &ps.Error
 	}
 
@@ -95,6 +96,22 @@ func Run(parser Parserish, input string, ws ...VoidParser) (result interface{},
 	return ret.Result, nil
 }
 
+// unterminatedComment replaces the error in ps with the whitespace parser's own when it was raised at
+// a comment that is never closed. The whitespace parser can't report that itself, because the parser
+// that called it goes on to fail on the comment and raises an error of its own.
+func unterminatedComment(ps *State) {
+	if ps.Error.unterminated != "" || ps.Error.pos > len(ps.Input) {
+		return
+	}
+	snap := ps.Snapshot()
+	ps.Pos = ps.Error.pos
+	ps.Recover()
+	ps.WS(ps)
+	if ps.Error.unterminated == "" {
+		ps.Restore(snap)
+	}
+}
+
 // Cut prevents backtracking beyond this point. Usually used after keywords when you
 // are sure this is the correct path. Improves performance and error reporting.
 func Cut() Parser {
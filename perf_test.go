@@ -1,6 +1,9 @@
 package goparsify
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func BenchmarkAny(b *testing.B) {
 	p := Any("hello", "goodbye", "help")
@@ -13,3 +16,59 @@ func BenchmarkAny(b *testing.B) {
 		_, _ = Run(p, "help me")
 	}
 }
+
+func BenchmarkStringLitLateEscape(b *testing.B) {
+	p := StringLit(`"`)
+	input := `"` + strings.Repeat("a", 20) + `\n` + strings.Repeat("b", 2000) + `"`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Run(p, input)
+	}
+}
+
+func BenchmarkStringLitManyEscaped(b *testing.B) {
+	p := Many(StringLit(`"`), ",")
+	input := strings.Repeat(`"hello\tworld \"quoted\" é",`, 1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Run(p, input)
+	}
+}
+
+func BenchmarkSmallSeq(b *testing.B) {
+	p := Seq("hello", "world")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Run(p, "hello world")
+	}
+}
+
+func BenchmarkNestedLists(b *testing.B) {
+	var value Parser
+	list := Seq("[", Some(&value, ","), "]")
+	value = Any(NumberLit(), list)
+	input := strings.Repeat("[1, [2, [3, 4], [5, [6]]], ", 200) + "7" + strings.Repeat("]", 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Run(value, input)
+	}
+}
+
+func BenchmarkStringLitMostlyEscapes(b *testing.B) {
+	p := StringLit(`"`)
+	input := `"` + strings.Repeat(`\"é\x41\n`, 5000) + `"`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Run(p, input)
+	}
+}
@@ -2,6 +2,7 @@ package goparsify
 
 import (
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -18,6 +19,14 @@ type Result struct {
 	Input  string
 	Start  int
 	End    int
+	// Opener and Closer are the delimiters matched by delimited literals such as StringLit,
+	// CustomStringLiteral and the regexp literals
+	Opener rune
+	Closer rune
+	// Raw is the text of a string literal including its quotes, set when the KeepQuotes option is used
+	Raw string
+	// Escapes is the number of escape sequences decoded by a string literal using CountEscapes
+	Escapes int
 }
 
 // String stringifies a node. This is only called from debug code.
@@ -39,3 +48,110 @@ func (r Result) String() string {
 
 	return r.Token
 }
+
+// Int64 returns .Result if it holds an int64, as set by NumberLit for integers.
+func (r Result) Int64() (int64, bool) {
+	i, ok := r.Result.(int64)
+	return i, ok
+}
+
+// Float64 returns .Result if it holds a float64, as set by NumberLit for floats.
+func (r Result) Float64() (float64, bool) {
+	f, ok := r.Result.(float64)
+	return f, ok
+}
+
+// AsInt64 is like Int64, but also converts a float64 with no fractional part that fits in an int64.
+func (r Result) AsInt64() (int64, bool) {
+	switch n := r.Result.(type) {
+	case int64:
+		return n, true
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// AsFloat64 is like Float64, but also converts an int64, which may lose precision for large values.
+func (r Result) AsFloat64() (float64, bool) {
+	switch n := r.Result.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// Clone returns a copy of the node that shares no slices with it, so that changing the children of
+// either, at any depth, leaves the other alone. Copying a Result directly shares its Child array.
+// The value in .Result is copied as is, so if it is a pointer or slice both will still refer to it.
+func (r Result) Clone() Result {
+	if r.Child != nil {
+		children := make([]Result, len(r.Child))
+		for i, child := range r.Child {
+			children[i] = child.Clone()
+		}
+		r.Child = children
+	}
+	return r
+}
+
+// Get follows .Child[i] for each of indices in turn and returns the node it ends up at, or nil if any
+// index is out of range. With no indices it returns the node itself. eg for a regexp replace literal
+// Get(1) is the replacement. The node returned is part of the tree, not a copy.
+func (r *Result) Get(indices ...int) *Result {
+	node := r
+	for _, i := range indices {
+		if i < 0 || i >= len(node.Child) {
+			return nil
+		}
+		node = &node.Child[i]
+	}
+	return node
+}
+
+// Walk calls fn on the node and then on each of its children in turn, depth first. When fn returns
+// false the children of that node are skipped.
+func (r *Result) Walk(fn func(*Result) bool) {
+	if !fn(r) {
+		return
+	}
+	for i := range r.Child {
+		r.Child[i].Walk(fn)
+	}
+}
+
+// FlattenOption changes how Flatten collects tokens.
+type FlattenOption func(*flattenOptions)
+
+type flattenOptions struct {
+	skipEmpty bool
+}
+
+// SkipEmpty leaves leaves with an empty Token out of the result of Flatten.
+func SkipEmpty() FlattenOption {
+	return func(o *flattenOptions) {
+		o.skipEmpty = true
+	}
+}
+
+// Flatten returns the Token of every leaf below the node, ie every node without children, in the order
+// they appear in the tree. For a regexp replace literal this is the pattern and the replacement.
+func (r Result) Flatten(opts ...FlattenOption) []string {
+	var options flattenOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	tokens := []string{}
+	r.Walk(func(node *Result) bool {
+		if len(node.Child) == 0 && (node.Token != "" || !options.skipEmpty) {
+			tokens = append(tokens, node.Token)
+		}
+		return true
+	})
+	return tokens
+}
@@ -1,6 +1,7 @@
 package goparsify
 
 import (
+	"math"
 	"math/big"
 	"testing"
 
@@ -13,3 +14,137 @@ func TestResult_String(t *testing.T) {
 	require.Equal(t, "10", Result{Result: 10}.String())
 	require.Equal(t, "10", Result{Result: big.NewInt(10)}.String())
 }
+
+func TestResult_Clone(t *testing.T) {
+	value := big.NewInt(1)
+	tree := Result{Token: "root", Result: value, Start: 1, End: 9, Opener: '/', Child: []Result{
+		{Token: "a", Child: []Result{{Token: "a1"}}},
+		{Token: "b"},
+	}}
+	clone := tree.Clone()
+	require.Equal(t, tree, clone)
+
+	clone.Child[0].Token = "changed"
+	clone.Child[0].Child[0].Token = "changed"
+	clone.Child = append(clone.Child, Result{Token: "c"})
+	require.Equal(t, "a", tree.Child[0].Token)
+	require.Equal(t, "a1", tree.Child[0].Child[0].Token)
+	require.Len(t, tree.Child, 2)
+
+	require.True(t, value == clone.Result, ".Result is shared")
+	require.Nil(t, Result{}.Clone().Child)
+}
+
+func TestResult_Get(t *testing.T) {
+	tree := Result{Token: "root", Child: []Result{
+		{Token: "a", Child: []Result{{Token: "a1"}, {Token: "a2"}}},
+		{Token: "b"},
+	}}
+
+	require.Equal(t, "root", tree.Get().Token)
+	require.Equal(t, "b", tree.Get(1).Token)
+	require.Equal(t, "a2", tree.Get(0, 1).Token)
+
+	require.Nil(t, tree.Get(2))
+	require.Nil(t, tree.Get(-1))
+	require.Nil(t, tree.Get(1, 0))
+	require.Nil(t, tree.Get(0, 1, 0))
+
+	t.Run("returns the node in the tree", func(t *testing.T) {
+		tree.Get(0, 0).Token = "changed"
+		require.Equal(t, "changed", tree.Child[0].Child[0].Token)
+	})
+
+	t.Run("regexp replace literal", func(t *testing.T) {
+		result, _ := runParser("/a+/b/", UnicodeRegexpReplaceLiteral())
+		require.Equal(t, "a+", result.Get(0).Token)
+		require.Equal(t, "b", result.Get(1).Token)
+	})
+}
+
+func TestResult_Walk(t *testing.T) {
+	tree := Result{Token: "root", Child: []Result{
+		{Token: "a", Child: []Result{{Token: "a1"}, {Token: "a2"}}},
+		{Token: "b", Child: []Result{{Token: "b1"}}},
+	}}
+
+	t.Run("visits every node in order", func(t *testing.T) {
+		var tokens []string
+		tree.Walk(func(node *Result) bool {
+			tokens = append(tokens, node.Token)
+			return true
+		})
+		require.Equal(t, []string{"root", "a", "a1", "a2", "b", "b1"}, tokens)
+	})
+
+	t.Run("skips children when fn returns false", func(t *testing.T) {
+		var tokens []string
+		tree.Walk(func(node *Result) bool {
+			tokens = append(tokens, node.Token)
+			return node.Token != "a"
+		})
+		require.Equal(t, []string{"root", "a", "b", "b1"}, tokens)
+	})
+
+	t.Run("nodes can be modified", func(t *testing.T) {
+		tree := Result{Child: []Result{{Token: "x"}}}
+		tree.Walk(func(node *Result) bool {
+			node.Token += "!"
+			return true
+		})
+		require.Equal(t, "x!", tree.Child[0].Token)
+	})
+}
+
+func TestResult_Flatten(t *testing.T) {
+	tree := Result{Token: "root", Child: []Result{
+		{Token: "a", Child: []Result{{Token: "a1"}, {}}},
+		{Token: "b"},
+	}}
+
+	require.Equal(t, []string{"a1", "", "b"}, tree.Flatten())
+	require.Equal(t, []string{"a1", "b"}, tree.Flatten(SkipEmpty()))
+	require.Equal(t, []string{"leaf"}, Result{Token: "leaf"}.Flatten())
+
+	t.Run("regexp replace literal", func(t *testing.T) {
+		result, _ := runParser("/a+/b/", UnicodeRegexpReplaceLiteral())
+		require.Equal(t, []string{"a+", "b"}, result.Flatten())
+	})
+}
+
+func TestResult_Numbers(t *testing.T) {
+	i, ok := Result{Result: int64(42)}.Int64()
+	require.True(t, ok)
+	require.Equal(t, int64(42), i)
+	_, ok = Result{Result: 42.0}.Int64()
+	require.False(t, ok)
+
+	f, ok := Result{Result: 4.5}.Float64()
+	require.True(t, ok)
+	require.Equal(t, 4.5, f)
+	_, ok = Result{Result: int64(4)}.Float64()
+	require.False(t, ok)
+
+	t.Run("coercion", func(t *testing.T) {
+		i, ok := Result{Result: 42.0}.AsInt64()
+		require.True(t, ok)
+		require.Equal(t, int64(42), i)
+		for _, value := range []interface{}{4.5, 1e300, math.NaN(), "42", nil} {
+			_, ok = Result{Result: value}.AsInt64()
+			require.False(t, ok, value)
+		}
+
+		f, ok := Result{Result: int64(4)}.AsFloat64()
+		require.True(t, ok)
+		require.Equal(t, 4.0, f)
+		_, ok = Result{Token: "4"}.AsFloat64()
+		require.False(t, ok)
+	})
+
+	t.Run("from NumberLit", func(t *testing.T) {
+		result, _ := runParser("0x10", NumberLit())
+		i, ok := result.Int64()
+		require.True(t, ok)
+		require.Equal(t, int64(16), i)
+	})
+}
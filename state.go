@@ -1,7 +1,9 @@
 package goparsify
 
 import (
+	"sort"
 	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -19,8 +21,15 @@ type State struct {
 	Error Error
 	// Called to determine what to ignore when WS is called, or when WS fires
 	WS VoidParser
+	// results is the unused part of the current block that Children hands out slices of
+	results []Result
+	// resultBlock is the size of the last block Children allocated, which the next one doubles
+	resultBlock int
 }
 
+// resultBlockSize is the most Results that Children allocates at a time
+const resultBlockSize = 128
+
 // ASCIIWhitespace matches any of the standard whitespace characters. It is faster
 // than the UnicodeWhitespace parser as it does not need to decode unicode runes.
 func ASCIIWhitespace(s *State) {
@@ -46,6 +55,128 @@ func UnicodeWhitespace(s *State) {
 	}
 }
 
+// WhitespaceWithLineComment returns a whitespace parser that skips unicode space characters like
+// UnicodeWhitespace, and also comments from prefix to the end of the line, eg
+// WhitespaceWithLineComment("#") for shell style comments. It can be passed to Run or assigned to
+// State.WS.
+func WhitespaceWithLineComment(prefix string) VoidParser {
+	return WhitespaceWithComments(LineComment(prefix))
+}
+
+// CommentStyle describes one kind of comment for WhitespaceWithComments
+type CommentStyle struct {
+	open  string
+	close string
+	// nested lets block comments contain further block comments of the same style
+	nested bool
+}
+
+// LineComment is a comment that runs from prefix to the end of the line, eg // or #
+func LineComment(prefix string) CommentStyle {
+	if prefix == "" {
+		panic("comment prefix must not be empty")
+	}
+	return CommentStyle{open: prefix}
+}
+
+// BlockComment is a comment that runs from open to the first close after it, eg /* and */
+func BlockComment(open, close string) CommentStyle {
+	if open == "" || close == "" {
+		panic("block comment delimiters must not be empty")
+	}
+	return CommentStyle{open: open, close: close}
+}
+
+// NestedBlockComment is a block comment that may contain others, which must each be closed before it
+// is, eg {- and -} in Haskell
+func NestedBlockComment(open, close string) CommentStyle {
+	style := BlockComment(open, close)
+	style.nested = true
+	return style
+}
+
+// WhitespaceWithComments returns a whitespace parser that skips unicode space characters, like
+// UnicodeWhitespace, along with comments in any of the given styles. With no styles it skips // and
+// /* */ comments. When comments start with the same text the longest opener is tried first.
+//
+// A block comment that is never closed is an error at the end of the input, and the comment is left
+// unconsumed. Error.Opener returns where it started. The parser that skipped whitespace will usually
+// go on to fail on the comment with an error of its own, so Run reports the unterminated comment in
+// its place.
+func WhitespaceWithComments(styles ...CommentStyle) VoidParser {
+	if len(styles) == 0 {
+		styles = []CommentStyle{LineComment("//"), BlockComment("/*", "*/")}
+	}
+	sorted := append([]CommentStyle(nil), styles...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].open) > len(sorted[j].open) })
+
+	return func(s *State) {
+		for {
+			UnicodeWhitespace(s)
+			style, ok := matchComment(s.Get(), sorted)
+			if !ok {
+				return
+			}
+			if style.close == "" {
+				end := strings.IndexByte(s.Input[s.Pos:], '\n')
+				if end < 0 {
+					s.Pos = len(s.Input)
+					return
+				}
+				s.Pos += end + 1
+				continue
+			}
+			end, ok := style.skipBlock(s.Input, s.Pos)
+			if !ok {
+				s.errorUnterminated("comment", style.close, s.Pos)
+				return
+			}
+			s.Pos = end
+		}
+	}
+}
+
+// matchComment returns the first of styles that opens a comment at the start of input
+func matchComment(input string, styles []CommentStyle) (CommentStyle, bool) {
+	for _, style := range styles {
+		if strings.HasPrefix(input, style.open) {
+			return style, true
+		}
+	}
+	return CommentStyle{}, false
+}
+
+// skipBlock returns the position just after the block comment opened at pos, or false if it is never
+// closed
+func (c CommentStyle) skipBlock(input string, pos int) (int, bool) {
+	pos += len(c.open)
+	if !c.nested {
+		end := strings.Index(input[pos:], c.close)
+		if end < 0 {
+			return 0, false
+		}
+		return pos + end + len(c.close), true
+	}
+
+	depth := 1
+	for pos < len(input) {
+		switch {
+		case strings.HasPrefix(input[pos:], c.close):
+			pos += len(c.close)
+			depth--
+			if depth == 0 {
+				return pos, true
+			}
+		case strings.HasPrefix(input[pos:], c.open):
+			pos += len(c.open)
+			depth++
+		default:
+			pos++
+		}
+	}
+	return 0, false
+}
+
 // NoWhitespace disables automatic whitespace matching
 func NoWhitespace(s *State) {
 
@@ -72,6 +203,56 @@ func (s *State) Get() string {
 	return s.Input[s.Pos:]
 }
 
+// RuneScanner reads the input of a State a rune at a time, keeping track of where each one is. It
+// doesn't move State.Pos, so a parser can scan ahead and set Pos from Pos() once it knows how much it
+// has matched.
+type RuneScanner struct {
+	input string
+	pos   int
+	valid bool
+}
+
+// Runes returns a RuneScanner that starts at the current position
+func (s *State) Runes() RuneScanner {
+	pos := s.Pos
+	if pos > len(s.Input) {
+		pos = len(s.Input)
+	}
+	return RuneScanner{input: s.Input, pos: pos, valid: true}
+}
+
+// Next returns the next rune, its size in bytes and its position, and moves past it. At the end of
+// the input the size is 0. A byte that isn't valid UTF-8 is returned on its own as utf8.RuneError
+// with a size of 1, and Valid reports false for it.
+func (sc *RuneScanner) Next() (r rune, size int, pos int) {
+	pos = sc.pos
+	r, size = utf8.DecodeRuneInString(sc.input[pos:])
+	sc.valid = r != utf8.RuneError || size != 1
+	sc.pos += size
+	return r, size, pos
+}
+
+// Peek returns the next rune and its size without moving past it
+func (sc *RuneScanner) Peek() (rune, int) {
+	return utf8.DecodeRuneInString(sc.input[sc.pos:])
+}
+
+// Valid reports whether the last rune returned by Next was valid UTF-8, so that a byte that couldn't
+// be decoded can be told apart from a U+FFFD written in the input.
+func (sc *RuneScanner) Valid() bool {
+	return sc.valid
+}
+
+// Pos returns the position of the next rune
+func (sc *RuneScanner) Pos() int {
+	return sc.pos
+}
+
+// Done reports whether the whole input has been read
+func (sc *RuneScanner) Done() bool {
+	return sc.pos >= len(sc.input)
+}
+
 // Preview of the the next x characters
 func (s *State) Preview(x int) string {
 	if s.Pos >= len(s.Input) {
@@ -87,16 +268,141 @@ func (s *State) Preview(x int) string {
 	return quoted
 }
 
+// LineCol returns the 1-based line and column of the byte offset pos in the input. Columns are counted
+// in runes rather than bytes. Offsets outside the input are taken to be at its start or end.
+func (s *State) LineCol(pos int) (line, col int) {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(s.Input) {
+		pos = len(s.Input)
+	}
+	lineStart := strings.LastIndexByte(s.Input[:pos], '\n') + 1
+	line = strings.Count(s.Input[:lineStart], "\n") + 1
+	col = utf8.RuneCountInString(s.Input[lineStart:pos]) + 1
+	return line, col
+}
+
+// errorContextSize is the number of bytes of input shown either side of an error by ErrorContext
+const errorContextSize = 20
+
+// ErrorContext returns the input around the position of the current error, followed by a line with a
+// caret under the error, for printing friendly error messages. At most a few characters either side
+// of the error are shown, and never more than the line the error is on.
+func (s *State) ErrorContext() string {
+	pos := s.Error.pos
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(s.Input) {
+		pos = len(s.Input)
+	}
+
+	start := pos - errorContextSize
+	if start < 0 {
+		start = 0
+	}
+	for start < pos && !utf8.RuneStart(s.Input[start]) {
+		start++
+	}
+	start += strings.LastIndexByte(s.Input[start:pos], '\n') + 1
+
+	end := pos + errorContextSize
+	if end > len(s.Input) {
+		end = len(s.Input)
+	}
+	for end > pos && end < len(s.Input) && !utf8.RuneStart(s.Input[end]) {
+		end--
+	}
+	if newline := strings.IndexByte(s.Input[pos:end], '\n'); newline >= 0 {
+		end = pos + newline
+	}
+
+	// keep tabs so that the caret lines up however wide they are shown
+	indent := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, s.Input[start:pos])
+	return s.Input[start:end] + "\n" + indent + "^"
+}
+
+// Children returns n empty Results for a parser to fill in as the children of its node. Small slices
+// are carved out of larger blocks shared by the whole parse, so deeply nested grammars make far fewer
+// allocations than if every node allocated its own. Appending past n is safe, it will reallocate.
+//
+// The first block is just big enough for n, and each after that is twice the last up to
+// resultBlockSize, so small parses cost no more than they would without the blocks.
+func (s *State) Children(n int) []Result {
+	if n > resultBlockSize/4 {
+		return make([]Result, n)
+	}
+	if len(s.results) < n {
+		size := s.resultBlock * 2
+		if size > resultBlockSize {
+			size = resultBlockSize
+		}
+		if size < n {
+			size = n
+		}
+		s.resultBlock = size
+		s.results = make([]Result, size)
+	}
+	children := s.results[:n:n]
+	s.results = s.results[n:]
+	return children
+}
+
 // ErrorHere raises an error at the current position.
 func (s *State) ErrorHere(expected string) {
-	s.Error.pos = s.Pos
+	s.errorAt(s.Pos, expected)
+}
+
+// errorAt raises an error at pos, dropping any unterminated literal details left over from an
+// earlier error.
+func (s *State) errorAt(pos int, expected string) {
+	s.Error.pos = pos
 	s.Error.expected = expected
+	s.Error.opener = 0
+	s.Error.unterminated = ""
+}
+
+// errorUnterminated raises an error at the end of the input for a literal opened at opener that was
+// never closed by closer. what names the kind of literal for the message, eg string literal.
+func (s *State) errorUnterminated(what string, closer string, opener int) {
+	s.Error.pos = len(s.Input)
+	s.Error.expected = closer
+	s.Error.opener = opener + 1
+	s.Error.unterminated = what
+}
+
+// Snapshot is the position and error of a State, as saved by State.Snapshot. It is a small value that
+// is cheap to copy and keep around.
+type Snapshot struct {
+	pos int
+	err Error
+}
+
+// Snapshot saves the current position and error, so that a parser can try something and go back with
+// Restore if it doesn't work out.
+func (s *State) Snapshot() Snapshot {
+	return Snapshot{pos: s.Pos, err: s.Error}
+}
+
+// Restore puts the position and error back to what they were when snap was taken. Anything matched
+// since is forgotten, along with any error raised.
+func (s *State) Restore(snap Snapshot) {
+	s.Pos = snap.pos
+	s.Error = snap.err
 }
 
 // Recover from the current error. Often called by combinators that can match
 // when one of their children succeed, but others have failed.
 func (s *State) Recover() {
 	s.Error.expected = ""
+	s.Error.opener = 0
+	s.Error.unterminated = ""
 }
 
 // Errored returns true if the current parser has failed.
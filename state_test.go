@@ -1,7 +1,9 @@
 package goparsify
 
 import (
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +52,29 @@ func TestState_Preview(t *testing.T) {
 	require.Equal(t, "asdfasdfas", NewState("asdfasdfasdf").Preview(10))
 }
 
+func TestState_LineCol(t *testing.T) {
+	ps := NewState("hello\nwörld\n\n!")
+	tests := []struct {
+		pos, line, col int
+	}{
+		{0, 1, 1},
+		{4, 1, 5},
+		{5, 1, 6},
+		{6, 2, 1},
+		{9, 2, 3},
+		{13, 3, 1},
+		{14, 4, 1},
+		{15, 4, 2},
+		{100, 4, 2},
+		{-1, 1, 1},
+	}
+	for _, test := range tests {
+		line, col := ps.LineCol(test.pos)
+		require.Equal(t, test.line, line, "line of %d", test.pos)
+		require.Equal(t, test.col, col, "col of %d", test.pos)
+	}
+}
+
 func TestWhitespaces(t *testing.T) {
 	p := Many(Any("hello", "world", "!"))
 
@@ -59,3 +84,248 @@ func TestWhitespaces(t *testing.T) {
 	_, err = Run(p, "hello world\u2005!", UnicodeWhitespace)
 	require.NoError(t, err)
 }
+
+func TestWhitespaceWithLineComment(t *testing.T) {
+	p := Many(Any("hello", "world", "!"))
+	ws := WhitespaceWithLineComment("#")
+
+	_, err := Run(p, "# greeting\nhello # first\n\u2005 # second\r\n  world!#", ws)
+	require.NoError(t, err)
+
+	_, err = Run(p, "hello // world", ws)
+	require.Equal(t, "left unparsed: // world", err.Error())
+
+	t.Run("literals skip comments too", func(t *testing.T) {
+		result, err := Run(NumberLit(), "-- the answer\n 42", WhitespaceWithLineComment("--"))
+		require.NoError(t, err)
+		require.Equal(t, int64(42), result)
+	})
+
+	t.Run("comment prefix inside a token is left alone", func(t *testing.T) {
+		ps := NewState(` "a # b" # done`)
+		ps.WS = ws
+		result := Result{}
+		StringLit(`"`)(ps, &result)
+		require.Equal(t, "a # b", result.Token)
+		ps.WS(ps)
+		require.Equal(t, "", ps.Get())
+	})
+}
+
+func TestWhitespaceWithComments(t *testing.T) {
+	p := Many(Any("hello", "world", "!"))
+	t.Run("default styles", func(t *testing.T) {
+		_, err := Run(p, "/* greeting */ hello // first\n/**/world/*\n*/!", WhitespaceWithComments())
+		require.NoError(t, err)
+	})
+
+	t.Run("literals skip comments too", func(t *testing.T) {
+		result, err := Run(Seq(NumberLit(), ",", StringLit(`"`)), `1 /* one */, "two" // end`, WhitespaceWithComments())
+		require.NoError(t, err)
+		require.Nil(t, result)
+	})
+
+	t.Run("longest opener first", func(t *testing.T) {
+		ws := WhitespaceWithComments(LineComment("--"), BlockComment("--[[", "]]"))
+		_, err := Run(p, "hello --[[ a\nlong comment ]] world -- end", ws)
+		require.NoError(t, err)
+	})
+
+	t.Run("nested block comments", func(t *testing.T) {
+		ws := WhitespaceWithComments(NestedBlockComment("{-", "-}"))
+		_, err := Run(p, "hello {- outer {- inner -} still outer -} world", ws)
+		require.NoError(t, err)
+
+		_, err = Run(p, "hello /* outer /* inner */ world */ !", WhitespaceWithComments())
+		require.Equal(t, "left unparsed: */ !", err.Error())
+	})
+
+	t.Run("unterminated block comment", func(t *testing.T) {
+		_, err := Run(p, "hello /* world", WhitespaceWithComments())
+		require.Equal(t, "offset 14: unterminated comment, expected closing */", err.Error())
+		opener, ok := err.(*Error).Opener()
+		require.True(t, ok)
+		require.Equal(t, 6, opener)
+
+		ws := WhitespaceWithComments(NestedBlockComment("(*", "*)"))
+		_, err = Run(p, "hello (* (* world *)", ws)
+		require.Equal(t, "offset 20: unterminated comment, expected closing *)", err.Error())
+	})
+
+	t.Run("unterminated comment outlives the parser that skipped it", func(t *testing.T) {
+		for _, p := range []Parser{Seq("a", "b"), Seq("a", NumberLit())} {
+			_, err := Run(p, "a /* x", WhitespaceWithComments())
+			require.Equal(t, "offset 6: unterminated comment, expected closing */", err.Error())
+			opener, ok := err.(*Error).Opener()
+			require.True(t, ok)
+			require.Equal(t, 2, opener)
+		}
+	})
+
+	t.Run("errors before the comment are kept", func(t *testing.T) {
+		_, err := Run(Seq("a", "b"), "a c /* x", WhitespaceWithComments())
+		require.Equal(t, "offset 2: expected b", err.Error())
+	})
+}
+
+func TestState_Runes(t *testing.T) {
+	ps := NewState("xaé\xff\uFFFD")
+	ps.Advance(1)
+	runes := ps.Runes()
+
+	type decoded struct {
+		r     rune
+		size  int
+		pos   int
+		valid bool
+	}
+	var got []decoded
+	for !runes.Done() {
+		r, size, pos := runes.Next()
+		got = append(got, decoded{r, size, pos, runes.Valid()})
+	}
+	require.Equal(t, []decoded{
+		{'a', 1, 1, true},
+		{'é', 2, 2, true},
+		{utf8.RuneError, 1, 4, false},
+		{utf8.RuneError, 3, 5, true},
+	}, got)
+
+	r, size, pos := runes.Next()
+	require.Equal(t, utf8.RuneError, r)
+	require.Equal(t, 0, size)
+	require.Equal(t, 8, pos)
+	require.Equal(t, 8, runes.Pos())
+	require.Equal(t, 1, ps.Pos, "scanning leaves the state alone")
+
+	t.Run("peek doesn't move", func(t *testing.T) {
+		runes := ps.Runes()
+		r, size := runes.Peek()
+		require.Equal(t, 'a', r)
+		require.Equal(t, 1, size)
+		require.Equal(t, 1, runes.Pos())
+	})
+}
+
+func TestState_Snapshot(t *testing.T) {
+	ps := NewState("hello world")
+	ps.Advance(5)
+	snap := ps.Snapshot()
+
+	ps.Advance(3)
+	ps.ErrorHere("world")
+	require.True(t, ps.Errored())
+
+	ps.Restore(snap)
+	require.Equal(t, 5, ps.Pos)
+	require.False(t, ps.Errored())
+
+	t.Run("restores an earlier error", func(t *testing.T) {
+		ps := NewState("hello")
+		ps.ErrorHere("goodbye")
+		snap := ps.Snapshot()
+		ps.Recover()
+		ps.Advance(2)
+
+		ps.Restore(snap)
+		require.Equal(t, 0, ps.Pos)
+		require.Equal(t, "offset 0: expected goodbye", ps.Error.Error())
+	})
+
+	t.Run("backtracking in a custom parser", func(t *testing.T) {
+		// matches a word, or a word and a number, going back to just the word when there's no number
+		number := NumberLit()
+		word := NewParser("word", func(ps *State, node *Result) {
+			Chars("a-z")(ps, node)
+			if ps.Errored() {
+				return
+			}
+			snap := ps.Snapshot()
+			Exact(":")(ps, TrashResult)
+			if !ps.Errored() {
+				number(ps, TrashResult)
+			}
+			if ps.Errored() {
+				ps.Restore(snap)
+			}
+		})
+		_, p := runParser("abc:12 rest", word)
+		require.Equal(t, " rest", p.Get())
+		_, p = runParser("abc:x", word)
+		require.False(t, p.Errored())
+		require.Equal(t, ":x", p.Get())
+	})
+}
+
+func TestState_ErrorContext(t *testing.T) {
+	ps := NewState("1 + * 2")
+	ps.Advance(4)
+	ps.ErrorHere("number")
+	require.Equal(t, "1 + * 2\n    ^", ps.ErrorContext())
+
+	t.Run("clamps to the surrounding text", func(t *testing.T) {
+		ps := NewState(strings.Repeat("a", 30) + "!" + strings.Repeat("b", 30))
+		ps.Advance(30)
+		ps.ErrorHere("letter")
+		require.Equal(t, strings.Repeat("a", 20)+"!"+strings.Repeat("b", 19)+"\n"+strings.Repeat(" ", 20)+"^", ps.ErrorContext())
+	})
+
+	t.Run("stays on one line", func(t *testing.T) {
+		ps := NewState("first\n\tsecond line\nthird")
+		ps.Advance(8)
+		ps.ErrorHere("letter")
+		require.Equal(t, "\tsecond line\n\t ^", ps.ErrorContext())
+	})
+
+	t.Run("does not split runes", func(t *testing.T) {
+		ps := NewState(strings.Repeat("é", 15) + "!" + strings.Repeat("é", 15))
+		ps.Advance(30)
+		ps.ErrorHere("letter")
+		require.Equal(t, strings.Repeat("é", 10)+"!"+strings.Repeat("é", 9)+"\n"+strings.Repeat(" ", 10)+"^", ps.ErrorContext())
+	})
+
+	t.Run("error at the end", func(t *testing.T) {
+		ps := NewState("abc")
+		ps.Advance(3)
+		ps.ErrorHere("more")
+		require.Equal(t, "abc\n   ^", ps.ErrorContext())
+	})
+
+	t.Run("error before the start", func(t *testing.T) {
+		ps := NewState("abc")
+		ps.errorAt(-1, "more")
+		require.Equal(t, "abc\n^", ps.ErrorContext())
+	})
+}
+
+func TestState_Children(t *testing.T) {
+	ps := NewState("")
+	a := ps.Children(2)
+	b := ps.Children(3)
+	require.Len(t, a, 2)
+	require.Len(t, b, 3)
+	require.Equal(t, Result{}, b[0])
+
+	// appending must not write into the next slice handed out
+	a[0].Token = "a"
+	a = append(a, Result{Token: "appended"})
+	require.Equal(t, "", b[0].Token)
+	require.Equal(t, "a", a[0].Token)
+
+	require.Len(t, ps.Children(resultBlockSize), resultBlockSize)
+	require.Len(t, ps.Children(0), 0)
+}
+
+func TestState_ChildrenBlockGrowth(t *testing.T) {
+	ps := NewState("")
+	ps.Children(2)
+	require.Equal(t, 2, ps.resultBlock)
+	ps.Children(1)
+	require.Equal(t, 4, ps.resultBlock)
+	require.Len(t, ps.results, 3)
+
+	for i := 0; i < 100; i++ {
+		ps.Children(5)
+	}
+	require.Equal(t, resultBlockSize, ps.resultBlock)
+}
@@ -0,0 +1,100 @@
+package goparsify
+
+import "io"
+
+// RunStream applies parser over and over to input read from r, calling fn with each result until the
+// input runs out. Only a window of the input is held in memory at a time, so very large inputs such as
+// log files can be parsed as long as each item in them is small.
+//
+// A result is only accepted once at least lookahead bytes of input follow it, or r is exhausted, so that
+// an item split across two reads isn't mistaken for a shorter one. lookahead should be at least as long
+// as the furthest any parser needs to look past the end of an item, and is taken to be at least 1, as
+// even an item that needs no lookahead could go on in the next read.
+//
+// The results passed to fn refer to the window they were parsed from: .Input is the window, and .Start
+// and .End are offsets into it. Errors report their offset into the whole stream.
+func RunStream(parser Parserish, r io.Reader, lookahead int, fn func(*Result) error, ws ...VoidParser) error {
+	p := Parsify(parser)
+	if lookahead < 1 {
+		lookahead = 1
+	}
+	chunk := make([]byte, 4096)
+	if lookahead > len(chunk) {
+		chunk = make([]byte, lookahead)
+	}
+
+	var window string
+	offset := 0
+	eof := false
+	more := func() error {
+		n, err := r.Read(chunk)
+		window += string(chunk[:n])
+		if err == io.EOF {
+			eof = true
+			return nil
+		}
+		return err
+	}
+
+	for {
+		if !eof && len(window) < 2*lookahead {
+			if err := more(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ps := NewState(window)
+		if len(ws) > 0 {
+			ps.WS = ws[0]
+		}
+		ps.WS(ps)
+		start := ps.Pos
+		if start == len(window) {
+			if eof {
+				return nil
+			}
+			if err := more(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result := Result{Input: window}
+		p(ps, &result)
+		if ps.Errored() {
+			// an unclosed comment is reported at the end of the window, so more is read in case it
+			// closes further on
+			unterminatedComment(ps)
+		}
+
+		reached := ps.Pos
+		if ps.Errored() && ps.Error.pos > reached {
+			reached = ps.Error.pos
+		}
+		if !eof && reached+lookahead > len(window) {
+			if err := more(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if ps.Errored() {
+			err := ps.Error
+			err.pos += offset
+			if err.opener > 0 {
+				err.opener += offset
+			}
+			return &err
+		}
+		if ps.Pos == start {
+			return UnparsedInputError{window[start:]}
+		}
+		if err := fn(&result); err != nil {
+			return err
+		}
+
+		window = window[ps.Pos:]
+		offset += ps.Pos
+	}
+}
@@ -0,0 +1,96 @@
+package goparsify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStream(t *testing.T) {
+	item := Seq(Chars("a-z"), "=", NumberLit(), ";")
+
+	t.Run("parses every item", func(t *testing.T) {
+		var keys []string
+		var values []interface{}
+		input := iotest.OneByteReader(strings.NewReader("a=1; bb=1234;\n ccc=-5.5;"))
+		err := RunStream(item, input, 8, func(r *Result) error {
+			keys = append(keys, r.Child[0].Token)
+			values = append(values, r.Child[2].Result)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "bb", "ccc"}, keys)
+		require.Equal(t, []interface{}{int64(1), int64(1234), -5.5}, values)
+	})
+
+	t.Run("doesn't split items between reads without lookahead", func(t *testing.T) {
+		var values []interface{}
+		input := iotest.OneByteReader(strings.NewReader("123456 7"))
+		err := RunStream(NumberLit(), input, 0, func(r *Result) error {
+			values = append(values, r.Result)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{int64(123456), int64(7)}, values)
+	})
+
+	t.Run("handles large inputs", func(t *testing.T) {
+		count := 0
+		input := strings.NewReader(strings.Repeat("abc=123;", 10000))
+		err := RunStream(item, input, 16, func(r *Result) error {
+			require.Equal(t, int64(123), r.Child[2].Result)
+			count++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 10000, count)
+	})
+
+	t.Run("reports errors with their offset in the stream", func(t *testing.T) {
+		input := strings.NewReader(strings.Repeat("abc=123;", 1000) + "abc=x;")
+		err := RunStream(item, input, 16, func(r *Result) error { return nil })
+		require.Equal(t, "offset 8004: expected number", err.Error())
+	})
+
+	t.Run("reports unterminated comments", func(t *testing.T) {
+		input := strings.NewReader("a=1; b= /* x")
+		err := RunStream(item, input, 4, func(r *Result) error { return nil }, WhitespaceWithComments())
+		require.Equal(t, "offset 12: unterminated comment, expected closing */", err.Error())
+		opener, ok := err.(*Error).Opener()
+		require.True(t, ok)
+		require.Equal(t, 8, opener)
+	})
+
+	t.Run("reads on for the end of a comment", func(t *testing.T) {
+		var values []interface{}
+		input := iotest.OneByteReader(strings.NewReader("a=1; b= /* a long comment */ 2;"))
+		err := RunStream(item, input, 4, func(r *Result) error {
+			values = append(values, r.Child[2].Result)
+			return nil
+		}, WhitespaceWithComments())
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{int64(1), int64(2)}, values)
+	})
+
+	t.Run("stops on callback errors", func(t *testing.T) {
+		stop := errors.New("stop")
+		count := 0
+		err := RunStream(item, strings.NewReader("a=1;b=2;c=3;"), 4, func(r *Result) error {
+			count++
+			if count == 2 {
+				return stop
+			}
+			return nil
+		})
+		require.Equal(t, stop, err)
+		require.Equal(t, 2, count)
+	})
+
+	t.Run("stops when nothing is consumed", func(t *testing.T) {
+		err := RunStream(Some(item), strings.NewReader("a=1;?"), 4, func(r *Result) error { return nil })
+		require.Equal(t, "left unparsed: ?", err.Error())
+	})
+}